@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+
+	"github.com/Scalu/sortablechallenge/matcher"
+)
+
+// printCounts prints the counts of a map sorted by key
+func printCounts(title string, counts map[string]int) {
+	fmt.Println(title)
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("  %-30s %d\n", key, counts[key])
+	}
+}
+
+// runEvaluate runs the evaluate command and returns the exit code. It matches labeled listings, reports precision,
+// recall and F1 and fails when they regress from the baseline.
+func runEvaluate(name string, arguments []string) int {
+	var labelsFileName, baselineFileName string
+	var writeBaseline bool
+	var tolerance float64
+	o, err := parseOptions(name, arguments, func(flagSet *flag.FlagSet) {
+		flagSet.StringVar(&labelsFileName, "labels", "labels.txt", "labeled listings file, listings with the product_name they should match")
+		flagSet.StringVar(&baselineFileName, "baseline", "", "evaluation baseline file to compare against")
+		flagSet.BoolVar(&writeBaseline, "write-baseline", false, "save this evaluation as the new baseline instead of comparing against it")
+		flagSet.Float64Var(&tolerance, "tolerance", 0.0, "how far a metric can drop below the baseline before failing")
+	})
+	if err != nil {
+		return 2
+	}
+	products := matcher.Products{FileName: o.ProductsFileName}
+	if err = o.getArchive().ImportJSONFromArchiveFile(&products); err != nil {
+		fmt.Println("Error importing products data:", err)
+		return 1
+	}
+	labeledListings, err := matcher.LoadLabeledListings(labelsFileName)
+	if err != nil {
+		return 1
+	}
+	fmt.Println("Evaluating", labeledListings.GetLabelCount(), "labeled listings against", products.GetProductCount(), "products")
+	productMatcher, err := o.getMatcher(&products)
+	if err != nil {
+		return 1
+	}
+	evaluation := matcher.Evaluate(productMatcher, labeledListings)
+	fmt.Printf("Precision: %.4f Recall: %.4f F1: %.4f\n", evaluation.Precision, evaluation.Recall, evaluation.F1)
+	fmt.Println("True positives:", evaluation.TruePositives, "false positives:", evaluation.FalsePositives,
+		"false negatives:", evaluation.FalseNegatives, "true negatives:", evaluation.TrueNegatives)
+	printCounts("False positives by manufacturer:", evaluation.FalsePositivesByManufacturer)
+	printCounts("False positives by stage:", evaluation.FalsePositivesByStage)
+	printCounts("False negatives by manufacturer:", evaluation.FalseNegativesByManufacturer)
+	printCounts("False negatives by stage:", evaluation.FalseNegativesByStage)
+	if baselineFileName == "" {
+		return 0
+	}
+	if writeBaseline {
+		if err = evaluation.Save(baselineFileName); err != nil {
+			return 1
+		}
+		fmt.Println("Baseline saved to", baselineFileName)
+		return 0
+	}
+	baseline, err := matcher.LoadEvaluation(baselineFileName)
+	if err != nil {
+		return 1
+	}
+	fmt.Printf("Baseline precision: %.4f recall: %.4f F1: %.4f\n", baseline.Precision, baseline.Recall, baseline.F1)
+	regressions := evaluation.CompareToBaseline(baseline, tolerance)
+	for _, regression := range regressions {
+		fmt.Println("REGRESSION:", regression)
+	}
+	if len(regressions) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/Scalu/sortablechallenge/matcher"
+)
+
+// printIssues prints a section of the catalog lint report
+func printIssues(title string, issues []matcher.CatalogIssue) {
+	fmt.Println(title, len(issues))
+	for _, issue := range issues {
+		fmt.Println("  " + issue.String())
+	}
+}
+
+// runLintCatalog runs the lint-catalog command and returns the exit code. It builds the product token index and
+// reports the catalog problems that hurt matching.
+func runLintCatalog(name string, arguments []string) int {
+	var reportFileName string
+	var maxEditDistance int
+	o, err := parseOptions(name, arguments, func(flagSet *flag.FlagSet) {
+		flagSet.StringVar(&reportFileName, "report", "", "optional JSON file the report is written to")
+		flagSet.IntVar(&maxEditDistance, "max-edit-distance", 1, "edits allowed between the letters of two models with the same numbers, or two manufacturers, reported as near duplicates")
+	})
+	if err != nil {
+		return 2
+	}
+	products := matcher.Products{FileName: o.ProductsFileName}
+	if err = o.getArchive().ImportJSONFromArchiveFile(&products); err != nil {
+		fmt.Println("Error importing products data:", err)
+		return 1
+	}
+	productMatcher, err := o.getMatcher(&products)
+	if err != nil {
+		return 1
+	}
+	catalogLint := matcher.LintCatalog(productMatcher, maxEditDistance)
+	printIssues("Duplicate products:", catalogLint.Duplicates)
+	printIssues("Subset products:", catalogLint.Subsets)
+	printIssues("Empty models:", catalogLint.EmptyModels)
+	printIssues("Near duplicate models:", catalogLint.NearDuplicateModels)
+	printIssues("Manufacturer spelling variants:", catalogLint.ManufacturerVariants)
+	fmt.Println("Found", catalogLint.IssueCount(), "issues in", catalogLint.ProductCount, "products")
+	if reportFileName != "" {
+		if err = catalogLint.Save(reportFileName); err != nil {
+			return 1
+		}
+		fmt.Println("Report saved to", reportFileName)
+	}
+	return 0
+}
@@ -0,0 +1,57 @@
+package matcher
+
+import (
+	"fmt"
+	"time"
+)
+
+// anachronismSlack is how much earlier than a product's announcement a listing may be dated, it covers the
+// time zones of announced dates compared to listing dates without a time
+const anachronismSlack = 24 * time.Hour
+
+// parseAnnouncedDate parses the product's announced date, a product with an unparsable date is treated as undated.
+// Products without an "announced-date" use their "announced_date" instead.
+func (p *Product) parseAnnouncedDate() {
+	if p.AnnouncedDate == "" {
+		p.AnnouncedDate = p.LegacyAnnouncedDate
+	}
+	announcedDate, err := parseDate(p.AnnouncedDate)
+	if err != nil {
+		fmt.Println("Date conversion error for product", p.ProductName, err)
+	}
+	p.announcedDate = announcedDate
+}
+
+// Announced returns the date the product was announced, a zero date if it's unknown
+func (p *Product) Announced() time.Time {
+	return p.announcedDate
+}
+
+// isAnnouncedAfter returns true when the product is known to have been announced after the listing date
+func (p *Product) isAnnouncedAfter(listingDate time.Time) bool {
+	if listingDate.IsZero() || p.announcedDate.IsZero() {
+		return false
+	}
+	return listingDate.Add(anachronismSlack).Before(p.announcedDate)
+}
+
+// getNewestProduct returns the product announced last, nil if any of the products is undated or if the last
+// announced date is shared by several products
+func getNewestProduct(products []*Product) (newestProduct *Product) {
+	tied := false
+	for _, product := range products {
+		if product.announcedDate.IsZero() {
+			return nil
+		}
+		if newestProduct == nil || product.announcedDate.After(newestProduct.announcedDate) {
+			newestProduct = product
+			tied = false
+		} else if product.announcedDate.Equal(newestProduct.announcedDate) {
+			tied = true
+		}
+	}
+	if tied {
+		return nil
+	}
+	return
+}
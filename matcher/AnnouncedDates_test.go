@@ -0,0 +1,80 @@
+package matcher
+
+import "testing"
+
+func TestAnachronismPenalty(t *testing.T) {
+	products := []*Product{
+		{ProductName: "Canon_PowerShot_SX210_IS", Manufacturer: "Canon", Family: "PowerShot", Model: "SX210 IS", AnnouncedDate: "2010-02-08T19:00:00.000-05:00"},
+		// older product files spell the key with an underscore
+		{ProductName: "Samsung_TL240", Manufacturer: "Samsung", Model: "TL240", LegacyAnnouncedDate: "2010-01-05"},
+	}
+	tests := []struct {
+		penalty              int
+		title                string
+		date                 string
+		productName          string
+		tokenOrderDifference int
+	}{
+		// the dates are ignored by default
+		{0, "Canon PowerShot SX210 IS", "2009-06-01", "Canon_PowerShot_SX210_IS", 0},
+		{3, "Canon PowerShot SX210 IS", "2009-06-01", "Canon_PowerShot_SX210_IS", 3},
+		{-1, "Canon PowerShot SX210 IS", "2009-06-01", "", 0},
+		{-1, "Samsung TL240", "2009-12-31", "", 0},
+		// listings dated the day of the announcement, later or not at all aren't anachronistic
+		{-1, "Canon PowerShot SX210 IS", "2010-02-08", "Canon_PowerShot_SX210_IS", 0},
+		{-1, "Canon PowerShot SX210 IS", "2010-06-01T12:00:00Z", "Canon_PowerShot_SX210_IS", 0},
+		{-1, "Canon PowerShot SX210 IS", "", "Canon_PowerShot_SX210_IS", 0},
+		{-1, "Samsung TL240", "2010-01-05", "Samsung_TL240", 0},
+	}
+	for _, test := range tests {
+		m := newTestMatcher(products, func(config *Config) { config.AnachronismPenalty = test.penalty })
+		matchResult := m.Match(&Listing{Title: test.title, Date: test.date})
+		productName := ""
+		if matchResult.Product != nil {
+			productName = matchResult.Product.ProductName
+		}
+		if productName != test.productName || matchResult.TokenOrderDifference != test.tokenOrderDifference {
+			t.Errorf("%q dated %q with penalty %d matched %q with token order difference %d, expected %q with %d", test.title, test.date,
+				test.penalty, productName, matchResult.TokenOrderDifference, test.productName, test.tokenOrderDifference)
+		}
+	}
+}
+
+func TestPreferRecentProducts(t *testing.T) {
+	tests := []struct {
+		powerShotDate string
+		ixusDate      string
+		productName   string
+	}{
+		{"2010-02-08", "2011-02-07", "Canon_IXUS_SX210"},
+		{"2011-02-07", "2010-02-08", "Canon_PowerShot_SX210"},
+		// undated or tied products are still ambiguous
+		{"", "2011-02-07", ""},
+		{"2010-02-08", "2010-02-08", ""},
+	}
+	for _, scoringModel := range []string{ScoringModelTokenOrder, ScoringModelIDF} {
+		for _, test := range tests {
+			products := newAmbiguousTestCatalog()
+			products[0].AnnouncedDate, products[1].AnnouncedDate = test.powerShotDate, test.ixusDate
+			// the listing is ambiguous unless the recent products are preferred
+			for _, preferRecent := range []bool{false, true} {
+				m := newTestMatcher(products, func(config *Config) {
+					config.ScoringModel = scoringModel
+					config.PreferRecentProducts = preferRecent
+				})
+				matchResult := m.Match(&Listing{Title: "Canon SX210 digital camera"})
+				productName, expectedProductName, expectedDecision := "", "", DecisionAmbiguous
+				if matchResult.Product != nil {
+					productName = matchResult.Product.ProductName
+				}
+				if preferRecent && test.productName != "" {
+					expectedProductName, expectedDecision = test.productName, DecisionMatched
+				}
+				if productName != expectedProductName || matchResult.Decision != expectedDecision {
+					t.Errorf("%s scoring with PowerShot announced %q, IXUS %q and prefer recent %v matched %q with decision %q, expected %q with %q",
+						scoringModel, test.powerShotDate, test.ixusDate, preferRecent, productName, matchResult.Decision, expectedProductName, expectedDecision)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package matcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// RankedCandidate is one of the best candidate products for a listing, with the rule that picked or rejected it
+type RankedCandidate struct {
+	ProductName          string  `json:"product_name"`
+	TokenOrderDifference int     `json:"token_order_difference"`
+	Confidence           float64 `json:"confidence,omitempty"` // only set by the idf scoring model
+	Rule                 string  `json:"rule"`
+}
+
+// ListingCandidates is the record written for each listing by ExportCandidates. The decision tells apart
+// ambiguous listings from listings without candidates.
+type ListingCandidates struct {
+	Listing    *Listing          `json:"listing"`
+	Decision   string            `json:"decision"`
+	Candidates []RankedCandidate `json:"candidates"`
+}
+
+// getCandidateRank orders the candidates by how far they got: the matched product, then the ones still in contention,
+// then the ones a decision rejected such as "worse than" or "above threshold", then the eliminated ones
+func getCandidateRank(candidate *CandidateExplanation) int {
+	switch {
+	case candidate.Eliminated != "":
+		return 3
+	case candidate.Decision == "matched":
+		return 0
+	case candidate.Decision == "" || strings.HasPrefix(candidate.Decision, "ambiguous with "):
+		return 1
+	}
+	return 2
+}
+
+// getTopCandidates ranks the candidates of the explanation, see getCandidateRank, then by confidence and token order
+// difference, and returns the best count of them. The matched product always comes first.
+func (e *Explanation) getTopCandidates(count int) (topCandidates []RankedCandidate) {
+	topCandidates = []RankedCandidate{}
+	candidates := append([]*CandidateExplanation{}, e.Candidates...)
+	sort.SliceStable(candidates, func(a, b int) bool {
+		if rankA, rankB := getCandidateRank(candidates[a]), getCandidateRank(candidates[b]); rankA != rankB {
+			return rankA < rankB
+		}
+		if candidates[a].Confidence != candidates[b].Confidence {
+			return candidates[a].Confidence > candidates[b].Confidence
+		}
+		return candidates[a].TokenOrderDifference < candidates[b].TokenOrderDifference
+	})
+	for _, candidate := range candidates[:min(count, len(candidates))] {
+		rule := candidate.Decision
+		if candidate.Eliminated != "" {
+			rule = candidate.Eliminated
+		} else if rule == "" {
+			rule = "undecided"
+		}
+		topCandidates = append(topCandidates, RankedCandidate{
+			ProductName:          candidate.ProductName,
+			TokenOrderDifference: candidate.TokenOrderDifference,
+			Confidence:           candidate.Confidence,
+			Rule:                 rule,
+		})
+	}
+	return
+}
+
+// getCandidatesRecord returns the listing's candidates record, nil if the candidates weren't kept
+func (l *Listing) getCandidatesRecord() *ListingCandidates {
+	if l.candidates == nil {
+		return nil
+	}
+	return &ListingCandidates{Listing: l, Decision: l.decision, Candidates: l.candidates}
+}
+
+// ExportCandidates export the top candidates of every listing, one JSON object per line, to the given filename
+func (l *Listings) ExportCandidates(filename string) (err error) {
+	candidatesFile, err := os.Create(filename)
+	if err != nil {
+		fmt.Println("Error creating file for candidates:", err)
+		return err
+	}
+	defer candidatesFile.Close()
+	jsonEncoder := json.NewEncoder(candidatesFile)
+	candidatesCount := 0
+	for _, listing := range l.listings {
+		candidatesRecord := listing.getCandidatesRecord()
+		if candidatesRecord == nil {
+			continue
+		}
+		candidatesCount++
+		err = jsonEncoder.Encode(candidatesRecord)
+		if err != nil {
+			fmt.Println("Error exporting candidates to file", filename, ":", err)
+			return err
+		}
+	}
+	fmt.Println("Done writing the candidates of", candidatesCount, "listings to", filename)
+	return nil
+}
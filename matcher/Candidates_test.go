@@ -0,0 +1,59 @@
+package matcher
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetTopCandidates(t *testing.T) {
+	explanation := &Explanation{Candidates: []*CandidateExplanation{
+		{ProductName: "eliminated", Eliminated: "missing token"},
+		// a short product rejected with a lower token order difference than the matched one
+		{ProductName: "above threshold", TokenOrderDifference: 5, Decision: "above threshold"},
+		{ProductName: "worse", TokenOrderDifference: 14, Decision: "worse than matched"},
+		{ProductName: "matched", TokenOrderDifference: 6, Decision: "matched"},
+		{ProductName: "undecided", TokenOrderDifference: 9},
+	}}
+	tests := []struct {
+		count      int
+		candidates []RankedCandidate
+	}{
+		{1, []RankedCandidate{{"matched", 6, 0, "matched"}}},
+		{6, []RankedCandidate{
+			{"matched", 6, 0, "matched"}, {"undecided", 9, 0, "undecided"}, {"above threshold", 5, 0, "above threshold"},
+			{"worse", 14, 0, "worse than matched"}, {"eliminated", 0, 0, "missing token"},
+		}},
+	}
+	for _, test := range tests {
+		if candidates := explanation.getTopCandidates(test.count); !reflect.DeepEqual(candidates, test.candidates) {
+			t.Errorf("top %d candidates %+v, expected %+v", test.count, candidates, test.candidates)
+		}
+	}
+}
+
+func TestMatchTopCandidates(t *testing.T) {
+	m := newTestMatcher(newTestCatalog(), func(config *Config) { config.TopCandidates = 2 })
+	tests := []struct {
+		title    string
+		decision string
+		rules    []string // product name and rule of each candidate
+	}{
+		{"Canon PowerShot SX210 IS", DecisionMatched, []string{"Canon_PowerShot_SX210_IS matched", "Canon_PowerShot_SX200_IS missing token 200"}},
+		{"Canon PowerShot SX200 IS", DecisionMatched, []string{"Canon_PowerShot_SX200_IS matched", "Canon_PowerShot_SX210_IS missing token 210"}},
+		{"Olympus Stylus Tough 6000", DecisionNoCandidates, []string{}},
+	}
+	for _, test := range tests {
+		matchResult := m.Match(&Listing{Title: test.title})
+		rules := []string{}
+		for _, candidate := range matchResult.Candidates {
+			rules = append(rules, candidate.ProductName+" "+candidate.Rule)
+		}
+		if matchResult.Decision != test.decision || !reflect.DeepEqual(rules, test.rules) {
+			t.Errorf("%q decided %q with candidates %q, expected %q with %q", test.title, matchResult.Decision, rules, test.decision, test.rules)
+		}
+	}
+	// the candidates are only kept when asked for
+	if matchResult := newTestMatcher(newTestCatalog(), nil).Match(&Listing{Title: "Canon PowerShot SX210 IS"}); matchResult.Candidates != nil {
+		t.Errorf("candidates kept without Config.TopCandidates: %+v", matchResult.Candidates)
+	}
+}
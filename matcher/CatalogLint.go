@@ -0,0 +1,205 @@
+package matcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// CatalogIssue is a problem found in the product catalog, with the products or values involved
+type CatalogIssue struct {
+	Products []string `json:"products,omitempty"`
+	Values   []string `json:"values,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+// String returns the issue on a single line
+func (ci CatalogIssue) String() string {
+	return strings.Join(append(append([]string{}, ci.Products...), ci.Values...), " | ") + ": " + ci.Detail
+}
+
+// CatalogLint lists the problems found in a product catalog that hurt matching
+type CatalogLint struct {
+	ProductCount int `json:"product_count"`
+	// Duplicates are products with the same manufacturer, family and model under different product names
+	Duplicates []CatalogIssue `json:"duplicates"`
+	// Subsets are pairs of products where the tokens of the first are all found in the second
+	Subsets []CatalogIssue `json:"subsets"`
+	// EmptyModels are products without any model token
+	EmptyModels []CatalogIssue `json:"empty_models"`
+	// NearDuplicateModels are products of the same manufacturer whose models have the same numbers and letters a few
+	// edits apart, e.g. "DSC-W310" and "DSCW310". Sibling models such as "D3100" and "D5100" aren't reported.
+	NearDuplicateModels []CatalogIssue `json:"near_duplicate_models"`
+	// ManufacturerVariants are manufacturer names spelled more than one way, or a few edits apart
+	ManufacturerVariants []CatalogIssue `json:"manufacturer_variants"`
+}
+
+// IssueCount returns the total number of issues found
+func (cl *CatalogLint) IssueCount() int {
+	return len(cl.Duplicates) + len(cl.Subsets) + len(cl.EmptyModels) + len(cl.NearDuplicateModels) + len(cl.ManufacturerVariants)
+}
+
+// LintCatalog checks the matcher's product catalog for duplicates, subsets, empty models, near duplicate models
+// and manufacturer spelling variants. Models with the same numbers whose letters are up to maxEditDistance edits apart,
+// and manufacturers up to maxEditDistance edits apart, are reported.
+func LintCatalog(m *Matcher, maxEditDistance int) (cl *CatalogLint) {
+	pt := m.productTokens
+	products := m.products.products
+	cl = &CatalogLint{
+		ProductCount:         len(products),
+		Duplicates:           []CatalogIssue{},
+		Subsets:              []CatalogIssue{},
+		EmptyModels:          []CatalogIssue{},
+		NearDuplicateModels:  []CatalogIssue{},
+		ManufacturerVariants: []CatalogIssue{},
+	}
+	// group the products by manufacturer, family and model tokens
+	productKeys := make([]string, len(products))
+	duplicateGroups := map[string][]string{}
+	duplicateKeys := []string{}
+	for productIndex, product := range products {
+		family := strings.Join(m.tokenizers.Family.Tokenize(product.Family), " ")
+		model := strings.Join(product.modelTokens, " ")
+		productKeys[productIndex] = product.manufacturerName + " / " + family + " / " + model
+		if _, found := duplicateGroups[productKeys[productIndex]]; !found {
+			duplicateKeys = append(duplicateKeys, productKeys[productIndex])
+		}
+		duplicateGroups[productKeys[productIndex]] = append(duplicateGroups[productKeys[productIndex]], product.ProductName)
+		if len(product.modelTokens) == 0 {
+			cl.EmptyModels = append(cl.EmptyModels, CatalogIssue{
+				Products: []string{product.ProductName},
+				Detail:   fmt.Sprintf("model %q has no tokens", product.Model),
+			})
+		}
+	}
+	for _, key := range duplicateKeys {
+		if len(duplicateGroups[key]) > 1 {
+			cl.Duplicates = append(cl.Duplicates, CatalogIssue{Products: duplicateGroups[key], Detail: key})
+		}
+	}
+	// a superset has all the tokens of the subset, so it's one of the products sharing the subset's first token
+	for _, product := range products {
+		if len(product.tokenList) == 0 {
+			continue
+		}
+		for _, possibleSuperset := range pt.tokens[product.tokenList[0]].products {
+			if possibleSuperset == product || !isSubsetOf(product.tokenList, possibleSuperset.tokenList) {
+				continue
+			}
+			extraTokens := []string{}
+			for _, tokenIndex := range possibleSuperset.tokenList {
+				if !pt.tokens[tokenIndex].hasProduct(product) {
+					extraTokens = append(extraTokens, pt.tokens[tokenIndex].value)
+				}
+			}
+			cl.Subsets = append(cl.Subsets, CatalogIssue{
+				Products: []string{product.ProductName, possibleSuperset.ProductName},
+				Detail:   "the second product adds " + strings.Join(extraTokens, " "),
+			})
+		}
+	}
+	// compare the models of each manufacturer's products, exact duplicates are already reported. Models with different
+	// numbers are different models however close they are, so only the letters of models with the same numbers are compared.
+	for firstIndex, firstProduct := range products {
+		firstLetters, firstNumbers := splitModelKey(firstProduct.modelTokens)
+		if firstLetters == "" && firstNumbers == "" {
+			continue
+		}
+		for secondIndex := firstIndex + 1; secondIndex < len(products); secondIndex++ {
+			secondProduct := products[secondIndex]
+			if secondProduct.manufacturerName != firstProduct.manufacturerName || productKeys[firstIndex] == productKeys[secondIndex] {
+				continue
+			}
+			secondLetters, secondNumbers := splitModelKey(secondProduct.modelTokens)
+			if secondNumbers != firstNumbers || (secondLetters == "" && secondNumbers == "") {
+				continue
+			}
+			if distance := getLevenshteinDistance(firstLetters, secondLetters); distance <= maxEditDistance {
+				cl.NearDuplicateModels = append(cl.NearDuplicateModels, CatalogIssue{
+					Products: []string{firstProduct.ProductName, secondProduct.ProductName},
+					Detail:   fmt.Sprintf("models %q and %q have the same numbers and letters %d edits apart", firstProduct.Model, secondProduct.Model, distance),
+				})
+			}
+		}
+	}
+	cl.lintManufacturers(products, maxEditDistance)
+	return
+}
+
+// splitModelKey returns the letters of the model tokens and their runs of digits, each joined without separators,
+// e.g. "dsc" "w310" gives "dscw" and "310", and "eos" "7d" "mark" "2" gives "eosdmark" and "7 2"
+func splitModelKey(modelTokens []string) (letters, numbers string) {
+	var letterBuilder strings.Builder
+	numberRuns := []string{}
+	for _, token := range modelTokens {
+		inDigits := false
+		for _, r := range token {
+			if unicode.IsDigit(r) {
+				if !inDigits {
+					numberRuns = append(numberRuns, "")
+					inDigits = true
+				}
+				numberRuns[len(numberRuns)-1] += string(r)
+				continue
+			}
+			inDigits = false
+			if unicode.IsLetter(r) {
+				letterBuilder.WriteRune(r)
+			}
+		}
+	}
+	return letterBuilder.String(), strings.Join(numberRuns, " ")
+}
+
+// lintManufacturers reports the manufacturers spelled more than one way, and the manufacturer names a few edits apart
+func (cl *CatalogLint) lintManufacturers(products []*Product, maxEditDistance int) {
+	spellings := map[string][]string{}
+	names := []string{}
+	for _, product := range products {
+		if product.manufacturerName == "" {
+			continue
+		}
+		if _, found := spellings[product.manufacturerName]; !found {
+			names = append(names, product.manufacturerName)
+		}
+		spelling := strings.TrimSpace(product.Manufacturer)
+		if !slices.Contains(spellings[product.manufacturerName], spelling) {
+			spellings[product.manufacturerName] = append(spellings[product.manufacturerName], spelling)
+		}
+	}
+	for firstIndex, firstName := range names {
+		if len(spellings[firstName]) > 1 {
+			cl.ManufacturerVariants = append(cl.ManufacturerVariants, CatalogIssue{
+				Values: spellings[firstName],
+				Detail: "spellings of " + firstName,
+			})
+		}
+		for _, secondName := range names[firstIndex+1:] {
+			if distance := getLevenshteinDistance(firstName, secondName); distance <= maxEditDistance {
+				cl.ManufacturerVariants = append(cl.ManufacturerVariants, CatalogIssue{
+					Values: []string{firstName, secondName},
+					Detail: fmt.Sprintf("manufacturers are %d edits apart", distance),
+				})
+			}
+		}
+	}
+}
+
+// Save writes the catalog lint report to a JSON file
+func (cl *CatalogLint) Save(filename string) (err error) {
+	reportFile, err := os.Create(filename)
+	if err != nil {
+		fmt.Println("Error creating catalog report file:", err)
+		return err
+	}
+	defer reportFile.Close()
+	jsonEncoder := json.NewEncoder(reportFile)
+	jsonEncoder.SetIndent("", "  ")
+	if err = jsonEncoder.Encode(cl); err != nil {
+		fmt.Println("Error writing catalog report to file", filename, ":", err)
+	}
+	return err
+}
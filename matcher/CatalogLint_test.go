@@ -0,0 +1,98 @@
+package matcher
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintCatalog(t *testing.T) {
+	m := newTestMatcher([]*Product{
+		{ProductName: "Canon_PowerShot_SX210_IS", Manufacturer: "Canon", Family: "PowerShot", Model: "SX210 IS"},
+		{ProductName: "Canon-PowerShot-SX210-IS", Manufacturer: "canon", Family: "Powershot", Model: "SX-210 IS"},
+		{ProductName: "Canon_SX210", Manufacturer: "Canon", Model: "SX210"},
+		{ProductName: "Kodak_EasyShare", Manufacturer: "Kodak", Family: "EasyShare", Model: "-"},
+		{ProductName: "Fujifilm_FinePix_S2500HD", Manufacturer: "Fujifilm", Family: "FinePix", Model: "S2500HD"},
+		{ProductName: "Fujifilm_FinePix_S2800HD", Manufacturer: "Fuji", Family: "FinePix", Model: "S2800HD"},
+		{ProductName: "Olympus_Tough_6000", Manufacturer: "Olympus", Family: "Tough", Model: "6000"},
+		{ProductName: "Olympus_Tough_8000", Manufacturer: "Olympos", Family: "Tough", Model: "8000"},
+	}, nil)
+	cl := LintCatalog(m, 1)
+	expected := &CatalogLint{
+		ProductCount: 8,
+		// the duplicates are written differently but have the same tokens, and aren't also reported as subsets
+		Duplicates: []CatalogIssue{
+			{Products: []string{"Canon_PowerShot_SX210_IS", "Canon-PowerShot-SX210-IS"}, Detail: "canon / powershot / sx 210 is"},
+		},
+		Subsets: []CatalogIssue{
+			{Products: []string{"Canon_SX210", "Canon_PowerShot_SX210_IS"}, Detail: "the second product adds powershot is"},
+			{Products: []string{"Canon_SX210", "Canon-PowerShot-SX210-IS"}, Detail: "the second product adds powershot is"},
+		},
+		EmptyModels:         []CatalogIssue{{Products: []string{"Kodak_EasyShare"}, Detail: `model "-" has no tokens`}},
+		NearDuplicateModels: []CatalogIssue{},
+		// "Fuji" is an alias of "Fujifilm", "Olympos" isn't one of "Olympus" but it's a single edit away
+		ManufacturerVariants: []CatalogIssue{
+			{Values: []string{"Canon", "canon"}, Detail: "spellings of canon"},
+			{Values: []string{"Fujifilm", "Fuji"}, Detail: "spellings of fujifilm"},
+			{Values: []string{"olympus", "olympos"}, Detail: "manufacturers are 1 edits apart"},
+		},
+	}
+	if !reflect.DeepEqual(cl, expected) {
+		t.Errorf("catalog lint %+v, expected %+v", cl, expected)
+	}
+	if issueCount := cl.IssueCount(); issueCount != 7 {
+		t.Errorf("%d issues counted, expected 7", issueCount)
+	}
+	// "olympos" is an edit away from "olympus", so it isn't reported when no edits are allowed
+	if variants := LintCatalog(m, 0).ManufacturerVariants; len(variants) != 2 {
+		t.Errorf("%d manufacturer variants without edits, expected the 2 spelling variants", len(variants))
+	}
+}
+
+func TestLintCatalogNearDuplicateModels(t *testing.T) {
+	m := newTestMatcher([]*Product{
+		{ProductName: "Nikon_D3100", Manufacturer: "Nikon", Family: "D", Model: "D3100"},
+		{ProductName: "Nikon_D5100", Manufacturer: "Nikon", Family: "D", Model: "D5100"},
+		{ProductName: "Canon_PowerShot_SX200_IS", Manufacturer: "Canon", Family: "PowerShot", Model: "SX200 IS"},
+		{ProductName: "Canon_PowerShot_SX210_IS", Manufacturer: "Canon", Family: "PowerShot", Model: "SX210 IS"},
+		{ProductName: "Sony_DSC-W310", Manufacturer: "Sony", Model: "DSC-W310"},
+		{ProductName: "Sony_DSCW310", Manufacturer: "Sony", Model: "DSCW310"},
+		{ProductName: "Sony_DSC-WX310", Manufacturer: "Sony", Model: "DSC-WX310"},
+		{ProductName: "Sony_DSC-W320", Manufacturer: "Sony", Model: "DSC-W320"},
+	}, nil)
+	// sibling models with different numbers aren't near duplicates, whatever the edit distance
+	tests := []struct {
+		maxEditDistance int
+		expected        [][]string
+	}{
+		{0, [][]string{{"Sony_DSC-W310", "Sony_DSCW310"}}},
+		{1, [][]string{{"Sony_DSC-W310", "Sony_DSCW310"}, {"Sony_DSC-W310", "Sony_DSC-WX310"}, {"Sony_DSCW310", "Sony_DSC-WX310"}}},
+	}
+	for _, test := range tests {
+		products := [][]string{}
+		for _, issue := range LintCatalog(m, test.maxEditDistance).NearDuplicateModels {
+			products = append(products, issue.Products)
+		}
+		if !reflect.DeepEqual(products, test.expected) {
+			t.Errorf("near duplicate models %v with %d edits, expected %v", products, test.maxEditDistance, test.expected)
+		}
+	}
+}
+
+func TestSplitModelKey(t *testing.T) {
+	tests := []struct {
+		modelTokens []string
+		letters     string
+		numbers     string
+	}{
+		{[]string{"d3100"}, "d", "3100"},
+		{[]string{"dsc", "w310"}, "dscw", "310"},
+		{[]string{"dscw310"}, "dscw", "310"},
+		{[]string{"eos", "7d", "mark", "2"}, "eosdmark", "7 2"},
+		{[]string{}, "", ""},
+	}
+	for _, test := range tests {
+		if letters, numbers := splitModelKey(test.modelTokens); letters != test.letters || numbers != test.numbers {
+			t.Errorf("splitModelKey(%q) = %q, %q, expected %q, %q", test.modelTokens, letters, numbers, test.letters, test.numbers)
+		}
+	}
+}
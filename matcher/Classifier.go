@@ -0,0 +1,125 @@
+package matcher
+
+import "fmt"
+
+// ListingClass tells what kind of item a listing is selling in relation to the product it matched
+type ListingClass int
+
+// listing classes set by classifyListing
+const (
+	PrimaryProduct ListingClass = iota
+	Accessory
+	Bundle
+)
+
+// String returns the name of the listing class
+func (lc ListingClass) String() string {
+	switch lc {
+	case Accessory:
+		return "accessory"
+	case Bundle:
+		return "bundle"
+	}
+	return "primary"
+}
+
+// the keywords are compared with the listing tokens, so they're written lower cased and accent folded like them
+
+// accessoryPrepositions are words that indicate an item made for another product, e.g. "battery for Canon ..."
+var accessoryPrepositions = map[string]bool{
+	"for": true, "compatible": true, "fits": true, "replacement": true, "pour": true, "fur": true, "para": true,
+}
+
+// accessoryNouns are words naming items that are commonly sold for a product rather than being the product
+var accessoryNouns = map[string]bool{
+	"case": true, "bag": true, "pouch": true, "sleeve": true, "skin": true, "housing": true, "cover": true, "protector": true,
+	"battery": true, "batteries": true, "charger": true, "adapter": true, "cable": true, "strap": true, "tripod": true,
+	"lens": true, "filter": true, "hood": true, "grip": true, "remote": true, "card": true,
+	"etui": true, "housse": true, "batterie": true, "chargeur": true, "tasche": true, "akku": true, "ladegerat": true,
+}
+
+// bundledAccessoryNouns are accessory nouns often listed after the product it comes with, e.g. "18-55mm lens" or
+// "8GB SD card", which don't make the listing an accessory on their own
+var bundledAccessoryNouns = map[string]bool{
+	"lens": true, "card": true,
+}
+
+// bundleKeywords are words that indicate items being sold together with the product
+var bundleKeywords = map[string]bool{
+	"with": true, "w": true, "plus": true, "including": true, "includes": true, "avec": true, "mit": true, "inkl": true,
+}
+
+// bundleNouns are words that name a bundle on their own
+var bundleNouns = map[string]bool{
+	"kit": true, "bundle": true, "pack": true, "lot": true,
+}
+
+// getFirstProductTokenPosition returns the position of the first listing token that is one of the product's tokens,
+// or the number of listing tokens if there is none
+func getFirstProductTokenPosition(pt *ProductTokens, listingTokens []string, product *Product) int {
+	if product == nil {
+		return len(listingTokens)
+	}
+	for position, listingToken := range listingTokens {
+		for _, tokenObjectIndex := range product.tokenList {
+			if pt.tokens[tokenObjectIndex].value == listingToken {
+				return position
+			}
+		}
+	}
+	return len(listingTokens)
+}
+
+// getPlusPositions returns the positions of the listing tokens following each "+" in the title, which the tokenizer drops
+func getPlusPositions(tokenizer Tokenizer, title string) (plusPositions []int) {
+	for index, character := range title {
+		if character == '+' {
+			plusPositions = append(plusPositions, len(tokenizer.Tokenize(title[:index])))
+		}
+	}
+	return plusPositions
+}
+
+// classifyListing tags a listing as the primary product, an accessory for it or a bundle containing it,
+// using keywords and their position relative to the first product token found in the listing's title
+func classifyListing(listingTokens []string, productStart int, plusPositions []int) (listingClass ListingClass, reason string) {
+	// anything naming an accessory before the product tokens means the listing is for something else
+	for position := 0; position < productStart && position < len(listingTokens); position++ {
+		listingToken := listingTokens[position]
+		if accessoryPrepositions[listingToken] {
+			return Accessory, fmt.Sprintf("keyword %q before the product tokens", listingToken)
+		}
+		if accessoryNouns[listingToken] {
+			return Accessory, fmt.Sprintf("accessory %q before the product tokens", listingToken)
+		}
+	}
+	// after the product tokens, accessories are what the product comes with when a bundle marker comes before them,
+	// otherwise the product is what they're for, e.g. "Nikon D3100 camera case" or "Nikon D3100 battery + charger"
+	bundleMarker := ""
+	plusIndex := 0
+	for position := productStart; position < len(listingTokens); position++ {
+		// a "+" only adds items when the part before it is the product itself, as nothing has been returned for it
+		for ; plusIndex < len(plusPositions) && plusPositions[plusIndex] <= position; plusIndex++ {
+			if plusPositions[plusIndex] > productStart && bundleMarker == "" {
+				bundleMarker = "+"
+			}
+		}
+		listingToken := listingTokens[position]
+		switch {
+		case bundleNouns[listingToken]:
+			return Bundle, fmt.Sprintf("keyword %q after the product tokens", listingToken)
+		case bundleKeywords[listingToken]:
+			if bundleMarker == "" {
+				bundleMarker = listingToken
+			}
+		case accessoryNouns[listingToken]:
+			if bundleMarker != "" {
+				return Bundle, fmt.Sprintf("accessory %q with bundle marker %q", listingToken, bundleMarker)
+			}
+			if !bundledAccessoryNouns[listingToken] {
+				return Accessory, fmt.Sprintf("accessory %q after the product tokens", listingToken)
+			}
+		}
+	}
+	return PrimaryProduct, ""
+}
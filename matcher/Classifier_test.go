@@ -0,0 +1,58 @@
+package matcher
+
+import "testing"
+
+// newTestMatcher returns a matcher for the given products with the default configuration changed by configure
+func newTestMatcher(products []*Product, configure func(config *Config)) *Matcher {
+	catalog := &Products{}
+	for _, product := range products {
+		catalog.Add(product)
+	}
+	config := DefaultConfig()
+	if configure != nil {
+		configure(&config)
+	}
+	return NewMatcher(catalog, config)
+}
+
+func TestClassifyListing(t *testing.T) {
+	m := newTestMatcher([]*Product{
+		{ProductName: "Canon_EOS_550D", Manufacturer: "Canon", Family: "EOS", Model: "550D"},
+		{ProductName: "Canon_EOS_Rebel_T3i", Manufacturer: "Canon", Family: "EOS Rebel", Model: "T3i"},
+		{ProductName: "Nikon_D3100", Manufacturer: "Nikon", Model: "D3100"},
+	}, func(config *Config) { config.DetectAccessories = true })
+	tests := []struct {
+		title          string
+		productName    string
+		classification ListingClass
+	}{
+		{"Canon EOS 550D 18-55mm Lens Kit", "Canon_EOS_550D", Bundle},
+		{"Canon EOS Rebel T3i 18-55mm IS II Lens Kit", "Canon_EOS_Rebel_T3i", Bundle},
+		{"Nikon D3100 + 8GB SD card", "Nikon_D3100", Bundle},
+		{"Nikon D3100 w/ 18-55mm lens", "Nikon_D3100", Bundle},
+		{"Nikon D3100 18-55mm lens", "Nikon_D3100", PrimaryProduct},
+		{"Nikon D3100 digital camera", "Nikon_D3100", PrimaryProduct},
+		{"Nikon D3100 digital camera + case", "Nikon_D3100", Bundle},
+		{"Nikon D3100 camera case", "", Accessory},
+		{"Nikon D3100 battery + charger", "", Accessory},
+		{"Nikon D3100 case with strap", "", Accessory},
+		{"Akku für Nikon D3100", "", Accessory},
+		{"Nikon D3100 Ladegerät", "", Accessory},
+		{"Nikon D3100 battery", "", Accessory},
+		{"Battery for Nikon D3100", "", Accessory},
+		{"Leather case Canon EOS 550D", "", Accessory},
+	}
+	for _, test := range tests {
+		matchResult := m.Match(&Listing{Title: test.title})
+		if matchResult.Classification != test.classification {
+			t.Errorf("%q classified as %v (%s), expected %v", test.title, matchResult.Classification, matchResult.ClassificationReason, test.classification)
+		}
+		productName := ""
+		if matchResult.Product != nil && matchResult.Decision == DecisionMatched {
+			productName = matchResult.Product.ProductName
+		}
+		if productName != test.productName {
+			t.Errorf("%q matched %q, expected %q", test.title, productName, test.productName)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package matcher
+
+import "fmt"
+
+// price outlier strategies for Config.PriceOutlierStrategy
+const (
+	PriceOutlierStrategyBestRange = "range"
+	PriceOutlierStrategyMAD       = "mad"
+)
+
+// Config holds the thresholds used while matching listings to products
+type Config struct {
+	// MaxTokenOrderDifference is the starting best token order difference, any candidate above it is never matched
+	MaxTokenOrderDifference int `json:"max_token_order_difference"`
+	// TokenOrderDifferenceAllowance is added to the product's token count to get the highest acceptable token order difference
+	TokenOrderDifferenceAllowance int `json:"token_order_difference_allowance"`
+	// MaxPriceRangeSpread is the ratio between the highest and lowest price of the accepted price range
+	MaxPriceRangeSpread float64 `json:"max_price_range_spread"`
+	// PriceVarianceFactor is multiplied by a listing's weight to get how far out of the accepted price range it may be
+	PriceVarianceFactor float64 `json:"price_variance_factor"`
+	// ManufacturerConflictPenalty is added to the token order difference when the listing's manufacturer field names
+	// another catalog manufacturer than the product's. A negative value vetoes the match instead, 0 ignores the field.
+	// When it's used, a manufacturer field agreeing with the product also fills in for a manufacturer missing from the title.
+	ManufacturerConflictPenalty int `json:"manufacturer_conflict_penalty"`
+	// AnachronismPenalty is added to the token order difference when the listing's date is before the product's
+	// announced date. A negative value vetoes the match instead, 0 ignores the dates.
+	AnachronismPenalty int `json:"anachronism_penalty"`
+	// PreferRecentProducts matches the most recently announced of the candidates that would otherwise make a listing ambiguous
+	PreferRecentProducts bool `json:"prefer_recent_products"`
+	// DetectAccessories enables the classifier that keeps accessories for a product out of it's results.
+	// It's off by default until it's been measured with the evaluate command.
+	DetectAccessories bool `json:"detect_accessories"`
+	// Explain records an Explanation of how each listing was matched or rejected
+	Explain bool `json:"explain"`
+	// PriceOutlierStrategy selects how irregularly priced listings are dropped, PriceOutlierStrategyBestRange or PriceOutlierStrategyMAD
+	PriceOutlierStrategy string `json:"price_outlier_strategy"`
+	// PriceOutlierThreshold is the number of scaled median absolute deviations of log price past which a listing is an outlier
+	PriceOutlierThreshold float64 `json:"price_outlier_threshold"`
+	// PriceStatistics adds the price statistics of each product to it's exported results
+	PriceStatistics bool `json:"price_statistics"`
+	// JoinModelNumbers matches model numbers regardless of punctuation, e.g. "DSC-W310", "DSCW310" and "DSC W310"
+	JoinModelNumbers bool `json:"join_model_numbers"`
+	// FuzzyTokens gives partial credit to listing tokens that are a prefix of a product token or within
+	// FuzzyMaxEditDistance edits of one, and splits listing tokens made of several product tokens, e.g. "powershotsx".
+	// Quantity tokens get partial credit for a product quantity of the same unit with a close value, e.g. "mp:12.1".
+	// Tokens with digits or shorter than FuzzyMinTokenLength must match exactly.
+	FuzzyTokens          bool `json:"fuzzy_tokens"`
+	FuzzyMaxEditDistance int  `json:"fuzzy_max_edit_distance"`
+	FuzzyMinTokenLength  int  `json:"fuzzy_min_token_length"`
+	// FuzzyTokenPenalty is the token order difference added for a loosely matched token, scaled by how dissimilar it is
+	FuzzyTokenPenalty int `json:"fuzzy_token_penalty"`
+	// ScoringModel selects how candidates are decided between, ScoringModelTokenOrder or ScoringModelIDF which weights
+	// tokens by their inverse document frequency and decides on a confidence from 0 to 1
+	ScoringModel string `json:"scoring_model"`
+	// MinConfidence is the lowest confidence the idf scoring model matches
+	MinConfidence float64 `json:"min_confidence"`
+	// AmbiguityMargin is how close in confidence the second best candidate may be before a listing is ambiguous
+	AmbiguityMargin float64 `json:"ambiguity_margin"`
+	// ListingIDF also weights tokens by their inverse document frequency over the listings matched so far
+	ListingIDF bool `json:"listing_idf"`
+	// TopCandidates is the number of ranked candidates kept with each listing's match, 0 keeps none
+	TopCandidates int `json:"top_candidates"`
+	// Workers is the number of goroutines matching listings, 0 uses one per CPU. The results don't depend on it.
+	Workers int `json:"workers"`
+}
+
+// Validate returns an error for a setting with a value the matcher doesn't know, which would otherwise be silently
+// replaced by the default behavior
+func (c *Config) Validate() error {
+	if c.PriceOutlierStrategy != PriceOutlierStrategyBestRange && c.PriceOutlierStrategy != PriceOutlierStrategyMAD {
+		return fmt.Errorf("unknown price outlier strategy %q, expected %q or %q", c.PriceOutlierStrategy, PriceOutlierStrategyBestRange, PriceOutlierStrategyMAD)
+	}
+	if c.ScoringModel != ScoringModelTokenOrder && c.ScoringModel != ScoringModelIDF {
+		return fmt.Errorf("unknown scoring model %q, expected %q or %q", c.ScoringModel, ScoringModelTokenOrder, ScoringModelIDF)
+	}
+	return nil
+}
+
+// DefaultConfig returns the configuration values the matcher was originally tuned with. Every matching behavior
+// added since is off until it's value or flag turns it on, only the tokenizer's accent folding changes the output.
+func DefaultConfig() Config {
+	return Config{
+		MaxTokenOrderDifference:       50,
+		TokenOrderDifferenceAllowance: 2,
+		MaxPriceRangeSpread:           2.0,
+		PriceVarianceFactor:           0.05,
+		PriceOutlierStrategy:          PriceOutlierStrategyBestRange,
+		PriceOutlierThreshold:         3.5,
+		ScoringModel:                  ScoringModelTokenOrder,
+		MinConfidence:                 0.4,
+		AmbiguityMargin:               0.1,
+		FuzzyMaxEditDistance:          1,
+		FuzzyMinTokenLength:           5,
+		FuzzyTokenPenalty:             4,
+	}
+}
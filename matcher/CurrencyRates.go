@@ -0,0 +1,225 @@
+package matcher
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// datedRate is an exchange rate, in units of the currency per US dollar, in effect from the given date
+type datedRate struct {
+	date        time.Time
+	unitsPerUSD float64
+}
+
+// CurrencyRates holds the exchange rates used to convert listing prices to US dollars
+type CurrencyRates struct {
+	rates             map[string][]datedRate // ISO 4217 code to rates sorted by date
+	unknownCurrencies map[string]int
+	mutex             sync.Mutex
+}
+
+// NewCurrencyRates returns an empty set of exchange rates, with only the US dollar
+func NewCurrencyRates() *CurrencyRates {
+	cr := &CurrencyRates{rates: map[string][]datedRate{}, unknownCurrencies: map[string]int{}}
+	cr.AddRate("USD", time.Time{}, 1.0)
+	return cr
+}
+
+// DefaultCurrencyRates returns the undated exchange rates the matcher used before rates could be loaded from a file
+func DefaultCurrencyRates() *CurrencyRates {
+	cr := NewCurrencyRates()
+	cr.AddRate("CAD", time.Time{}, 1.34)
+	cr.AddRate("EUR", time.Time{}, 0.92)
+	cr.AddRate("GBP", time.Time{}, 0.79)
+	return cr
+}
+
+// AddRate adds an exchange rate in units of the currency per US dollar, in effect from the given date.
+// A zero date is used for rates that don't depend on the date.
+func (cr *CurrencyRates) AddRate(currency string, date time.Time, unitsPerUSD float64) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	rates := cr.rates[currency]
+	// keep the rates sorted by date, rates are usually added in order so this is normally an append
+	rateIndex := sort.Search(len(rates), func(index int) bool { return rates[index].date.After(date) })
+	rates = append(rates, datedRate{})
+	copy(rates[rateIndex+1:], rates[rateIndex:])
+	rates[rateIndex] = datedRate{date: date, unitsPerUSD: unitsPerUSD}
+	cr.rates[currency] = rates
+}
+
+// GetRate returns the rate in units of the currency per US dollar in effect at the given date. The latest rate is
+// used for a zero date, the earliest one for a date before all the rates.
+func (cr *CurrencyRates) GetRate(currency string, date time.Time) (unitsPerUSD float64, found bool) {
+	rates := cr.rates[strings.ToUpper(strings.TrimSpace(currency))]
+	if len(rates) == 0 {
+		return 0, false
+	}
+	if date.IsZero() {
+		return rates[len(rates)-1].unitsPerUSD, true
+	}
+	rateIndex := sort.Search(len(rates), func(index int) bool { return rates[index].date.After(date) })
+	if rateIndex > 0 {
+		rateIndex--
+	}
+	return rates[rateIndex].unitsPerUSD, true
+}
+
+// ToUSD converts a price to US dollars. Unknown currencies are counted for the UnknownCurrencies summary.
+func (cr *CurrencyRates) ToUSD(price float64, currency string, date time.Time) (usdPrice float64, found bool) {
+	unitsPerUSD, found := cr.GetRate(currency, date)
+	if !found || unitsPerUSD <= 0 {
+		cr.mutex.Lock()
+		cr.unknownCurrencies[strings.ToUpper(strings.TrimSpace(currency))]++
+		cr.mutex.Unlock()
+		return 0, false
+	}
+	return price / unitsPerUSD, true
+}
+
+// UnknownCurrencies returns the number of prices that could not be converted for each unknown currency
+func (cr *CurrencyRates) UnknownCurrencies() map[string]int {
+	cr.mutex.Lock()
+	defer cr.mutex.Unlock()
+	unknownCurrencies := map[string]int{}
+	for currency, count := range cr.unknownCurrencies {
+		unknownCurrencies[currency] = count
+	}
+	return unknownCurrencies
+}
+
+// printUnknownCurrencies prints a one line summary of the unknown currencies, if there were any
+func (cr *CurrencyRates) printUnknownCurrencies() {
+	unknownCurrencies := cr.UnknownCurrencies()
+	if len(unknownCurrencies) == 0 {
+		return
+	}
+	currencies := make([]string, 0, len(unknownCurrencies))
+	for currency := range unknownCurrencies {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	summary := []string{}
+	for _, currency := range currencies {
+		if currency == "" {
+			summary = append(summary, fmt.Sprintf("missing (%d)", unknownCurrencies[currency]))
+		} else {
+			summary = append(summary, fmt.Sprintf("%s (%d)", currency, unknownCurrencies[currency]))
+		}
+	}
+	fmt.Println("Warning prices with unknown currencies were ignored:", strings.Join(summary, ", "))
+}
+
+// parseDate parses the dates found in rates files, listings and products, an empty value returns a zero date
+func parseDate(value string) (date time.Time, err error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err = time.Parse("2006-01-02", value)
+	if err != nil {
+		date, err = time.Parse(time.RFC3339, value)
+	}
+	return
+}
+
+// LoadCurrencyRates loads exchange rates from an ECB style XML file if the filename ends in .xml, or otherwise
+// from a CSV file with "date,currency,units per USD" records. The date may be empty for an undated rate.
+func LoadCurrencyRates(filename string) (cr *CurrencyRates, err error) {
+	ratesFile, err := os.Open(filename)
+	if err != nil {
+		fmt.Println("Error opening currency rates file:", filename, ", error:", err)
+		return nil, err
+	}
+	defer ratesFile.Close()
+	cr = NewCurrencyRates()
+	if strings.HasSuffix(strings.ToLower(filename), ".xml") {
+		err = cr.loadECBRates(ratesFile)
+	} else {
+		err = cr.loadCSVRates(ratesFile)
+	}
+	if err != nil {
+		fmt.Println("Error loading currency rates file:", filename, ", error:", err)
+		return nil, err
+	}
+	return cr, nil
+}
+
+// loadCSVRates loads "date,currency,units per USD" records, skipping a header line and lines starting with #
+func (cr *CurrencyRates) loadCSVRates(reader io.Reader) (err error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.Comment = '#'
+	csvReader.FieldsPerRecord = 3
+	csvReader.TrimLeadingSpace = true
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if strings.EqualFold(record[0], "date") {
+			continue
+		}
+		date, err := parseDate(record[0])
+		if err != nil {
+			return err
+		}
+		unitsPerUSD, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil {
+			return err
+		}
+		cr.AddRate(record[1], date, unitsPerUSD)
+	}
+}
+
+// ecbEnvelope is the layout of the European Central Bank's reference rates files, with rates in units per euro
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string  `xml:"currency,attr"`
+			Rate     float64 `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// loadECBRates loads the daily euro reference rates and converts them to rates per US dollar
+func (cr *CurrencyRates) loadECBRates(reader io.Reader) (err error) {
+	envelope := ecbEnvelope{}
+	if err = xml.NewDecoder(reader).Decode(&envelope); err != nil {
+		return err
+	}
+	// the files list the most recent day first, add the oldest rates first so they don't need to be sorted
+	for dayIndex := len(envelope.Days) - 1; dayIndex >= 0; dayIndex-- {
+		day := envelope.Days[dayIndex]
+		date, err := parseDate(day.Time)
+		if err != nil {
+			return err
+		}
+		dollarsPerEuro := 0.0
+		for _, rate := range day.Rates {
+			if strings.EqualFold(rate.Currency, "USD") {
+				dollarsPerEuro = rate.Rate
+			}
+		}
+		if dollarsPerEuro <= 0 {
+			return fmt.Errorf("no USD rate for %s", day.Time)
+		}
+		cr.AddRate("EUR", date, 1/dollarsPerEuro)
+		for _, rate := range day.Rates {
+			if !strings.EqualFold(rate.Currency, "USD") {
+				cr.AddRate(rate.Currency, date, rate.Rate/dollarsPerEuro)
+			}
+		}
+	}
+	return nil
+}
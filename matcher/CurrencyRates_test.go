@@ -0,0 +1,122 @@
+package matcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeRatesFile writes a rates file in a temporary directory and returns it's name
+func writeRatesFile(t *testing.T, name, content string) string {
+	filename := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+// checkRate checks the rate of a currency at a date, given as YYYY-MM-DD or empty for the latest rate
+func checkRate(t *testing.T, cr *CurrencyRates, currency, date string, expected float64) {
+	parsedDate, err := parseDate(date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unitsPerUSD, found := cr.GetRate(currency, parsedDate)
+	if !found || unitsPerUSD < expected-1e-9 || unitsPerUSD > expected+1e-9 {
+		t.Errorf("%s rate at %q is %v, %v, expected %v", currency, date, unitsPerUSD, found, expected)
+	}
+}
+
+func TestLoadECBRates(t *testing.T) {
+	filename := writeRatesFile(t, "eurofxref-hist.xml", `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2011-01-04">
+			<Cube currency="USD" rate="1.25"/>
+			<Cube currency="GBP" rate="0.75"/>
+		</Cube>
+		<Cube time="2011-01-03">
+			<Cube currency="USD" rate="1.6"/>
+			<Cube currency="GBP" rate="0.8"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`)
+	cr, err := LoadCurrencyRates(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the euro rates are converted to rates per US dollar
+	checkRate(t, cr, "EUR", "2011-01-03", 0.625)
+	checkRate(t, cr, "GBP", "2011-01-03", 0.5)
+	checkRate(t, cr, "EUR", "2011-01-04", 0.8)
+	checkRate(t, cr, "GBP", "2011-01-04", 0.6)
+	checkRate(t, cr, "usd", "2011-01-04", 1)
+	if _, err = LoadCurrencyRates(writeRatesFile(t, "no-usd.xml", `<Envelope><Cube><Cube time="2011-01-03"><Cube currency="GBP" rate="0.8"/></Cube></Cube></Envelope>`)); err == nil {
+		t.Error("expected an error for a day without a USD rate")
+	}
+}
+
+func TestLoadCSVRates(t *testing.T) {
+	filename := writeRatesFile(t, "rates.csv", `date,currency,units per USD
+# undated rates are used for listings without a date
+,JPY,80
+2011-01-03,CAD,1.1
+2011-01-05, cad, 1.2
+`)
+	cr, err := LoadCurrencyRates(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRate(t, cr, "JPY", "", 80)
+	checkRate(t, cr, "JPY", "2011-06-01", 80)
+	checkRate(t, cr, "CAD", "2011-01-03", 1.1)
+	checkRate(t, cr, "CAD", "2011-01-04", 1.1)
+	checkRate(t, cr, "CAD", "2011-01-05", 1.2)
+	if _, found := cr.GetRate("DATE", time.Time{}); found {
+		t.Error("the header line was loaded as a rate")
+	}
+	if _, err = LoadCurrencyRates(writeRatesFile(t, "bad.csv", "2011-01-03,CAD,abc\n")); err == nil {
+		t.Error("expected an error for a rate that isn't a number")
+	}
+}
+
+func TestGetRateDateFallback(t *testing.T) {
+	cr := NewCurrencyRates()
+	for _, rate := range []struct {
+		date        string
+		unitsPerUSD float64
+	}{{"2011-01-10", 1.3}, {"2011-01-01", 1.1}, {"2011-01-05", 1.2}} {
+		date, _ := parseDate(rate.date)
+		cr.AddRate("CAD", date, rate.unitsPerUSD)
+	}
+	// a date before the rates uses the earliest one, a zero date or a date after them the latest one
+	checkRate(t, cr, "CAD", "2010-12-31", 1.1)
+	checkRate(t, cr, "CAD", "2011-01-07", 1.2)
+	checkRate(t, cr, "CAD", "2011-02-01", 1.3)
+	checkRate(t, cr, "CAD", "", 1.3)
+	if _, found := cr.ToUSD(100, "XYZ", time.Time{}); found {
+		t.Error("converted a price in an unknown currency")
+	}
+	if unknownCurrencies := cr.UnknownCurrencies(); unknownCurrencies["XYZ"] != 1 {
+		t.Errorf("unknown currencies %v, expected XYZ once", unknownCurrencies)
+	}
+}
+
+func TestGetPriceWithReplacedRates(t *testing.T) {
+	listing := &Listing{Title: "Canon PowerShot SX210 IS", Currency: "CAD", Price: "200.00"}
+	rates := NewCurrencyRates()
+	rates.AddRate("CAD", time.Time{}, 2)
+	if price := listing.GetPrice(rates, -1); price != 100 {
+		t.Errorf("price %v, expected 100", price)
+	}
+	replacedRates := NewCurrencyRates()
+	replacedRates.AddRate("CAD", time.Time{}, 1.25)
+	if price := listing.GetPrice(replacedRates, -1); price != 160 {
+		t.Errorf("price %v with the replaced rates, expected 160", price)
+	}
+	if price := listing.GetPrice(NewCurrencyRates(), -1); price != -1 {
+		t.Errorf("price %v without a CAD rate, expected the default price", price)
+	}
+}
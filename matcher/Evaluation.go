@@ -0,0 +1,184 @@
+package matcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// evaluation stages for false positives, false negatives use the listing's decision
+const (
+	stageWrongProduct      = "wrong product"
+	stageExpectedUnmatched = "expected unmatched"
+)
+
+// labeledListing is a listing with the name of the product it should be matched to, empty if it should stay unmatched
+type labeledListing struct {
+	Listing
+	ExpectedProductName string `json:"product_name"`
+}
+
+// LabeledListings holds ground truth listing to product pairs
+type LabeledListings struct {
+	labels []*labeledListing
+}
+
+// LoadLabeledListings loads labeled listings, one JSON object per line, from the given filename. Unlike the products
+// and listings the labels are never looked for in the data archive, they're written by hand.
+func LoadLabeledListings(filename string) (ll *LabeledListings, err error) {
+	labelsFile, err := os.Open(filename)
+	if err != nil {
+		fmt.Println("Error opening labels file:", filename, ", error:", err)
+		return nil, err
+	}
+	defer labelsFile.Close()
+	ll = &LabeledListings{}
+	decoder := json.NewDecoder(labelsFile)
+	for {
+		label := &labeledListing{}
+		if err = decoder.Decode(label); err == io.EOF {
+			return ll, nil
+		}
+		if err != nil {
+			fmt.Println("Error decoding labels file:", filename, ", error:", err)
+			return nil, err
+		}
+		ll.labels = append(ll.labels, label)
+	}
+}
+
+// GetLabelCount returns the number of labeled listings
+func (ll *LabeledListings) GetLabelCount() int {
+	return len(ll.labels)
+}
+
+// Evaluation holds the precision and recall of a matching run against labeled listings
+type Evaluation struct {
+	TruePositives                int            `json:"true_positives"`
+	FalsePositives               int            `json:"false_positives"`
+	FalseNegatives               int            `json:"false_negatives"`
+	TrueNegatives                int            `json:"true_negatives"`
+	Precision                    float64        `json:"precision"`
+	Recall                       float64        `json:"recall"`
+	F1                           float64        `json:"f1"`
+	FalsePositivesByManufacturer map[string]int `json:"false_positives_by_manufacturer"`
+	FalseNegativesByManufacturer map[string]int `json:"false_negatives_by_manufacturer"`
+	FalsePositivesByStage        map[string]int `json:"false_positives_by_stage"`
+	FalseNegativesByStage        map[string]int `json:"false_negatives_by_stage"`
+}
+
+// Evaluate runs the full matching pipeline on the labeled listings and compares the matches to the labels.
+// The matcher's previous results are cleared first, so every evaluation starts from the same state.
+func Evaluate(m *Matcher, labeledListings *LabeledListings) (evaluation *Evaluation) {
+	m.resetResults()
+	listings := &Listings{}
+	for _, label := range labeledListings.labels {
+		listings.Add(&Listing{Title: label.Title, Manufacturer: label.Manufacturer, Currency: label.Currency, Price: label.Price, Date: label.Date})
+	}
+	m.MatchListings(listings)
+	productsByName := map[string]*Product{}
+	for _, product := range m.products.products {
+		productsByName[product.ProductName] = product
+	}
+	evaluation = &Evaluation{
+		FalsePositivesByManufacturer: map[string]int{},
+		FalseNegativesByManufacturer: map[string]int{},
+		FalsePositivesByStage:        map[string]int{},
+		FalseNegativesByStage:        map[string]int{},
+	}
+	for labelIndex, label := range labeledListings.labels {
+		listing := listings.listings[labelIndex]
+		expectedProduct := productsByName[label.ExpectedProductName]
+		if label.ExpectedProductName != "" && expectedProduct == nil {
+			fmt.Println("Warning labeled product", label.ExpectedProductName, "is not in the catalog")
+		}
+		manufacturer := label.Manufacturer
+		if expectedProduct != nil {
+			manufacturer = expectedProduct.Manufacturer
+		}
+		matchedProduct := listing.match
+		switch {
+		case matchedProduct == nil && label.ExpectedProductName == "":
+			evaluation.TrueNegatives++
+		case matchedProduct != nil && matchedProduct == expectedProduct:
+			evaluation.TruePositives++
+		case matchedProduct == nil:
+			evaluation.FalseNegatives++
+			evaluation.FalseNegativesByManufacturer[manufacturer]++
+			evaluation.FalseNegativesByStage[listing.decision]++
+		default:
+			// a match to the wrong product is both a false positive and a false negative
+			evaluation.FalsePositives++
+			evaluation.FalsePositivesByManufacturer[matchedProduct.Manufacturer]++
+			if label.ExpectedProductName == "" {
+				evaluation.FalsePositivesByStage[stageExpectedUnmatched]++
+			} else {
+				evaluation.FalsePositivesByStage[stageWrongProduct]++
+				evaluation.FalseNegatives++
+				evaluation.FalseNegativesByManufacturer[manufacturer]++
+				evaluation.FalseNegativesByStage[stageWrongProduct]++
+			}
+		}
+	}
+	if evaluation.TruePositives+evaluation.FalsePositives > 0 {
+		evaluation.Precision = float64(evaluation.TruePositives) / float64(evaluation.TruePositives+evaluation.FalsePositives)
+	}
+	if evaluation.TruePositives+evaluation.FalseNegatives > 0 {
+		evaluation.Recall = float64(evaluation.TruePositives) / float64(evaluation.TruePositives+evaluation.FalseNegatives)
+	}
+	if evaluation.Precision+evaluation.Recall > 0 {
+		evaluation.F1 = 2 * evaluation.Precision * evaluation.Recall / (evaluation.Precision + evaluation.Recall)
+	}
+	return
+}
+
+// CompareToBaseline returns a description of every metric that dropped more than the tolerance below the baseline
+func (e *Evaluation) CompareToBaseline(baseline *Evaluation, tolerance float64) (regressions []string) {
+	metrics := []struct {
+		name             string
+		value, baseValue float64
+	}{
+		{"precision", e.Precision, baseline.Precision},
+		{"recall", e.Recall, baseline.Recall},
+		{"F1", e.F1, baseline.F1},
+	}
+	for _, metric := range metrics {
+		if metric.value < metric.baseValue-tolerance {
+			regressions = append(regressions, fmt.Sprintf("%s dropped from %.4f to %.4f", metric.name, metric.baseValue, metric.value))
+		}
+	}
+	return
+}
+
+// LoadEvaluation loads an evaluation previously saved with Save, used as a baseline
+func LoadEvaluation(filename string) (evaluation *Evaluation, err error) {
+	evaluationFile, err := os.Open(filename)
+	if err != nil {
+		fmt.Println("Error opening evaluation file:", filename, ", error:", err)
+		return nil, err
+	}
+	defer evaluationFile.Close()
+	evaluation = &Evaluation{}
+	if err = json.NewDecoder(evaluationFile).Decode(evaluation); err != nil {
+		fmt.Println("Error decoding evaluation file:", filename, ", error:", err)
+		return nil, err
+	}
+	return evaluation, nil
+}
+
+// Save writes the evaluation in JSON format to the given filename
+func (e *Evaluation) Save(filename string) (err error) {
+	evaluationFile, err := os.Create(filename)
+	if err != nil {
+		fmt.Println("Error creating evaluation file:", err)
+		return err
+	}
+	defer evaluationFile.Close()
+	jsonEncoder := json.NewEncoder(evaluationFile)
+	jsonEncoder.SetIndent("", "  ")
+	if err = jsonEncoder.Encode(e); err != nil {
+		fmt.Println("Error writing evaluation to file", filename, ":", err)
+	}
+	return err
+}
@@ -0,0 +1,60 @@
+package matcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	labelsFileName := filepath.Join(t.TempDir(), "labels.txt")
+	labels := `{"title": "Sony DSC-W310", "manufacturer": "Sony", "currency": "USD", "price": "99.99", "product_name": "Sony_Cyber-shot_DSC-W310"}
+{"title": "Canon PowerShot SX210 IS", "manufacturer": "Canon", "currency": "USD", "price": "199.99", "product_name": "Canon_PowerShot_SX200_IS"}
+{"title": "Samsung TL240", "manufacturer": "Samsung", "currency": "USD", "price": "149.99", "product_name": "Samsung_TL240"}
+{"title": "Olympus Stylus Tough 6000", "manufacturer": "Olympus", "currency": "USD", "price": "129.99", "product_name": ""}
+{"title": "Canon PowerShot SX200 IS", "manufacturer": "Canon", "currency": "USD", "price": "179.99", "product_name": ""}
+`
+	if err := os.WriteFile(labelsFileName, []byte(labels), 0644); err != nil {
+		t.Fatal(err)
+	}
+	labeledListings, err := LoadLabeledListings(labelsFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if labeledListings.GetLabelCount() != 5 {
+		t.Fatalf("loaded %d labels, expected 5", labeledListings.GetLabelCount())
+	}
+	m := newTestMatcher(newTestCatalog(), nil)
+	// evaluating twice with the same matcher gives the same evaluation
+	for run := 1; run <= 2; run++ {
+		evaluation := Evaluate(m, labeledListings)
+		if evaluation.TruePositives != 2 || evaluation.FalsePositives != 2 || evaluation.FalseNegatives != 1 || evaluation.TrueNegatives != 1 {
+			t.Errorf("run %d evaluated %+v", run, evaluation)
+		}
+		if evaluation.FalsePositivesByStage[stageWrongProduct] != 1 || evaluation.FalsePositivesByStage[stageExpectedUnmatched] != 1 {
+			t.Errorf("run %d false positives by stage %v", run, evaluation.FalsePositivesByStage)
+		}
+		if evaluation.Precision != 0.5 || evaluation.Recall != 2.0/3.0 {
+			t.Errorf("run %d precision %v and recall %v, expected 0.5 and 0.667", run, evaluation.Precision, evaluation.Recall)
+		}
+		if listingCount := len(m.Products().products[1].Result().Listings); listingCount != 1 {
+			t.Errorf("run %d left %d listings in the SX210 results, expected 1", run, listingCount)
+		}
+	}
+}
+
+func TestLoadLabeledListingsMissingFile(t *testing.T) {
+	if _, err := LoadLabeledListings(filepath.Join(t.TempDir(), "labels.txt")); err == nil {
+		t.Error("expected an error for a missing labels file")
+	}
+}
+
+func TestCompareToBaseline(t *testing.T) {
+	baseline := &Evaluation{Precision: 0.9, Recall: 0.8, F1: 0.85}
+	if regressions := (&Evaluation{Precision: 0.89, Recall: 0.9, F1: 0.89}).CompareToBaseline(baseline, 0.02); len(regressions) != 0 {
+		t.Errorf("regressions %q within the tolerance", regressions)
+	}
+	if regressions := (&Evaluation{Precision: 0.85, Recall: 0.8, F1: 0.82}).CompareToBaseline(baseline, 0.02); len(regressions) != 2 {
+		t.Errorf("regressions %q, expected precision and F1", regressions)
+	}
+}
@@ -0,0 +1,91 @@
+package matcher
+
+// CandidateExplanation describes how a candidate product was evaluated for a listing
+type CandidateExplanation struct {
+	ProductName string `json:"product_name"`
+	// TokenPositions holds the listing token position of each product token, -1 for a missing token
+	TokenPositions       []int   `json:"token_positions"`
+	TokenOrderDifference int     `json:"token_order_difference"`
+	Confidence           float64 `json:"confidence,omitempty"` // only set by the idf scoring model
+	Eliminated           string  `json:"eliminated,omitempty"`
+	Decision             string  `json:"decision,omitempty"`
+}
+
+// Explanation describes how a listing was matched or why it was rejected
+type Explanation struct {
+	Title                string                  `json:"title"`
+	ListingTokens        []string                `json:"listing_tokens"`
+	Candidates           []*CandidateExplanation `json:"candidates"`
+	Decision             string                  `json:"decision"`
+	MatchedProduct       string                  `json:"matched_product,omitempty"`
+	Confidence           float64                 `json:"confidence,omitempty"` // only set by the idf scoring model
+	Classification       string                  `json:"classification,omitempty"`
+	ClassificationReason string                  `json:"classification_reason,omitempty"`
+	PriceRejection       *PriceRejection         `json:"price_rejection,omitempty"`
+	candidates           map[*Product]*CandidateExplanation
+}
+
+// newExplanation returns an empty explanation for the listing
+func newExplanation(listing *Listing, listingTokens []string) *Explanation {
+	return &Explanation{
+		Title:         listing.Title,
+		ListingTokens: listingTokens,
+		Candidates:    []*CandidateExplanation{},
+		candidates:    map[*Product]*CandidateExplanation{},
+	}
+}
+
+// getCandidate returns the candidate explanation for the product, adding it if needed
+func (e *Explanation) getCandidate(product *Product) *CandidateExplanation {
+	candidate, found := e.candidates[product]
+	if !found {
+		candidate = &CandidateExplanation{ProductName: product.ProductName}
+		e.candidates[product] = candidate
+		e.Candidates = append(e.Candidates, candidate)
+	}
+	return candidate
+}
+
+// explainCandidate records the latest evaluation of a candidate product. Does nothing if explanations are disabled.
+func (e *Explanation) explainCandidate(product *Product, tokenPositions []int, tokenOrderDifference int, eliminated string) {
+	if e == nil {
+		return
+	}
+	candidate := e.getCandidate(product)
+	candidate.TokenPositions = append([]int{}, tokenPositions...)
+	candidate.TokenOrderDifference = tokenOrderDifference
+	candidate.Eliminated = eliminated
+}
+
+// scoreCandidate records the confidence in a candidate
+func (e *Explanation) scoreCandidate(product *Product, confidence float64) {
+	if e == nil {
+		return
+	}
+	e.getCandidate(product).Confidence = confidence
+}
+
+// eliminateCandidate records why a previously accepted candidate was eliminated
+func (e *Explanation) eliminateCandidate(product *Product, eliminated string) {
+	if e == nil {
+		return
+	}
+	e.getCandidate(product).Eliminated = eliminated
+}
+
+// decideCandidate records the decision made for a candidate that survived elimination
+func (e *Explanation) decideCandidate(product *Product, decision string) {
+	if e == nil {
+		return
+	}
+	e.getCandidate(product).Decision = decision
+}
+
+// rejectPrice records why a matched listing was dropped by the price check
+func (e *Explanation) rejectPrice(priceRejection *PriceRejection) {
+	if e == nil {
+		return
+	}
+	e.PriceRejection = priceRejection
+	e.Decision = DecisionPriceRejected
+}
@@ -0,0 +1,79 @@
+package matcher
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExplanation(t *testing.T) {
+	if matchResult := newTestMatcher(newTestCatalog(), nil).Match(&Listing{Title: "Canon PowerShot SX210 IS"}); matchResult.Explanation != nil {
+		t.Error("explanation recorded without Config.Explain")
+	}
+	m := newTestMatcher(newTestCatalog(), func(config *Config) { config.Explain = true })
+	explanation := m.Match(&Listing{Title: "Canon PowerShot SX210 IS black"}).Explanation
+	if explanation == nil {
+		t.Fatal("no explanation recorded")
+	}
+	if explanation.Decision != DecisionMatched || explanation.MatchedProduct != "Canon_PowerShot_SX210_IS" {
+		t.Errorf("explanation decided %q for %q", explanation.Decision, explanation.MatchedProduct)
+	}
+	if !reflect.DeepEqual(explanation.ListingTokens, []string{"canon", "powershot", "sx", "210", "is", "black"}) {
+		t.Errorf("listing tokens %q", explanation.ListingTokens)
+	}
+	candidates := map[string]*CandidateExplanation{}
+	for _, candidate := range explanation.Candidates {
+		candidates[candidate.ProductName] = candidate
+	}
+	if len(candidates) != 2 {
+		t.Fatalf("expected the 2 Canon products as candidates, found %d", len(candidates))
+	}
+	if matched := candidates["Canon_PowerShot_SX210_IS"]; !reflect.DeepEqual(matched.TokenPositions, []int{0, 1, 2, 3, 4}) ||
+		matched.Eliminated != "" || matched.Decision != "matched" {
+		t.Errorf("matched candidate %+v", matched)
+	}
+	if eliminated := candidates["Canon_PowerShot_SX200_IS"]; eliminated.Eliminated != "missing token 200" {
+		t.Errorf("other candidate %+v, expected it to be missing the 200 token", eliminated)
+	}
+	explanation = m.Match(&Listing{Title: "Olympus Stylus"}).Explanation
+	if explanation.Decision != DecisionNoCandidates || len(explanation.Candidates) != 0 {
+		t.Errorf("unmatched listing explained with decision %q and %d candidates", explanation.Decision, len(explanation.Candidates))
+	}
+}
+
+func TestExportExplanations(t *testing.T) {
+	m := newTestMatcher(newTestCatalog(), func(config *Config) { config.Explain = true })
+	listings := &Listings{}
+	titles := []string{"Sony DSC-W310", "Olympus Stylus", "Canon PowerShot SX210 IS"}
+	for _, title := range titles {
+		listings.Add(&Listing{Title: title, Currency: "USD", Price: "199.99"})
+	}
+	m.MatchListings(listings)
+	filename := filepath.Join(t.TempDir(), "explain.txt")
+	if err := listings.ExportExplanations(filename); err != nil {
+		t.Fatal(err)
+	}
+	explanationsFile, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer explanationsFile.Close()
+	// one explanation per line, in listing order
+	scanner := bufio.NewScanner(explanationsFile)
+	lineCount := 0
+	for ; scanner.Scan(); lineCount++ {
+		explanation := &Explanation{}
+		if err = json.Unmarshal(scanner.Bytes(), explanation); err != nil {
+			t.Fatal(err)
+		}
+		if lineCount < len(titles) && explanation.Title != titles[lineCount] {
+			t.Errorf("line %d explains %q, expected %q", lineCount+1, explanation.Title, titles[lineCount])
+		}
+	}
+	if lineCount != len(titles) {
+		t.Errorf("wrote %d explanations, expected %d", lineCount, len(titles))
+	}
+}
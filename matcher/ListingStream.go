@@ -0,0 +1,204 @@
+package matcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// listingStreamBatchSize is the number of listings decoded before they are matched together
+const listingStreamBatchSize = 1024
+
+// ListingStream matches listings as they are decoded instead of loading them all in memory first.
+// Unmatched listings are written out and let go of as they are found. Matched listings, with their explanations and
+// candidates, are kept until Close since the price check needs all of a product's listings, so memory use grows with
+// the number of matched listings rather than the number of listings.
+// implementes JSONDecoder
+type ListingStream struct {
+	FileName            string
+	matcher             *Matcher
+	batch               []*Listing
+	matched             []*Listing // matched listings in listing order, written out by Close
+	unmatchedFile       *os.File
+	unmatchedEncoder    *json.Encoder
+	explanationsFile    *os.File
+	explanationsEncoder *json.Encoder
+	candidatesFile      *os.File
+	candidatesEncoder   *json.Encoder
+	ambiguousFile       *os.File
+	ambiguousEncoder    *json.Encoder
+	listingCount        int
+	unmatchedCount      int
+	outcomeCounts       OutcomeCounts
+}
+
+// NewListingStream returns a stream writing unmatched listings to unmatchedFileName, and the listing
+// explanations to explanationsFileName if it isn't empty. Config.ListingIDF can't be streamed, it weights the tokens
+// by their frequency over all of the listings before any is matched.
+func NewListingStream(m *Matcher, unmatchedFileName, explanationsFileName string) (ls *ListingStream, err error) {
+	if m.config.ScoringModel == ScoringModelIDF && m.config.ListingIDF {
+		err = fmt.Errorf("listing idf weights need every listing before matching, they can't be streamed")
+		fmt.Println("Error creating listing stream:", err)
+		return nil, err
+	}
+	ls = &ListingStream{matcher: m}
+	ls.unmatchedFile, err = os.Create(unmatchedFileName)
+	if err != nil {
+		fmt.Println("Error creating file for unmatched listings:", err)
+		return nil, err
+	}
+	ls.unmatchedEncoder = json.NewEncoder(ls.unmatchedFile)
+	if explanationsFileName != "" {
+		ls.explanationsFile, err = os.Create(explanationsFileName)
+		if err != nil {
+			fmt.Println("Error creating file for explanations:", err)
+			ls.unmatchedFile.Close()
+			return nil, err
+		}
+		ls.explanationsEncoder = json.NewEncoder(ls.explanationsFile)
+	}
+	return ls, nil
+}
+
+// SetCandidatesFile makes the stream write the top candidates of each listing to the given file,
+// the matcher needs Config.TopCandidates to be set
+func (ls *ListingStream) SetCandidatesFile(candidatesFileName string) (err error) {
+	ls.candidatesFile, err = os.Create(candidatesFileName)
+	if err != nil {
+		fmt.Println("Error creating file for candidates:", err)
+		return err
+	}
+	ls.candidatesEncoder = json.NewEncoder(ls.candidatesFile)
+	return nil
+}
+
+// SetAmbiguousFile makes the stream write the ambiguous listings, with the names of their competing products,
+// to the given file instead of the unmatched listings file
+func (ls *ListingStream) SetAmbiguousFile(ambiguousFileName string) (err error) {
+	ls.ambiguousFile, err = os.Create(ambiguousFileName)
+	if err != nil {
+		fmt.Println("Error creating file for ambiguous listings:", err)
+		return err
+	}
+	ls.ambiguousEncoder = json.NewEncoder(ls.ambiguousFile)
+	return nil
+}
+
+// GetOutcomeCounts returns the number of listings with each outcome, complete once the stream is closed
+func (ls *ListingStream) GetOutcomeCounts() OutcomeCounts {
+	return ls.outcomeCounts
+}
+
+// GetFileName used by JSONArchive util
+func (ls *ListingStream) GetFileName() string {
+	if ls.FileName != "" {
+		return ls.FileName
+	}
+	return "listings.txt"
+}
+
+// Decode used by JSONArchive util, matches the listings once a batch has been decoded
+func (ls *ListingStream) Decode(decoder *json.Decoder) (err error) {
+	listing := &Listing{}
+	err = decoder.Decode(&listing)
+	if err != nil {
+		return
+	}
+	ls.listingCount++
+	ls.batch = append(ls.batch, listing)
+	if len(ls.batch) >= listingStreamBatchSize {
+		err = ls.matchBatch()
+	}
+	return
+}
+
+// matchBatch matches the batched listings and writes out the ones left unmatched. The batch is emptied even if
+// writing fails, so Close doesn't match it again.
+func (ls *ListingStream) matchBatch() (err error) {
+	defer func() { ls.batch = ls.batch[:0] }()
+	matchResults := ls.matcher.matchAll(ls.batch)
+	for listingIndex, listing := range ls.batch {
+		listing.recordMatch(matchResults[listingIndex])
+		if listing.match != nil {
+			ls.matched = append(ls.matched, listing)
+			continue
+		}
+		if err = ls.writeUnmatched(listing); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeUnmatched writes an unmatched listing, it's explanation and it's candidates. Ambiguous listings go to the
+// ambiguous listings file when there is one.
+func (ls *ListingStream) writeUnmatched(listing *Listing) (err error) {
+	ls.outcomeCounts.add(listing)
+	if ls.ambiguousEncoder != nil && listing.Outcome() == OutcomeAmbiguous {
+		if err = ls.ambiguousEncoder.Encode(listing.getAmbiguousRecord()); err != nil {
+			fmt.Println("Error exporting ambiguous listing:", err)
+			return err
+		}
+		return ls.writeDetails(listing)
+	}
+	ls.unmatchedCount++
+	if err = ls.unmatchedEncoder.Encode(listing); err != nil {
+		fmt.Println("Error exporting unmatched listing:", err)
+		return err
+	}
+	return ls.writeDetails(listing)
+}
+
+// writeDetails writes the listing's explanation and candidates if they are being exported
+func (ls *ListingStream) writeDetails(listing *Listing) (err error) {
+	if ls.explanationsEncoder != nil && listing.explanation != nil {
+		if err = ls.explanationsEncoder.Encode(listing.explanation); err != nil {
+			fmt.Println("Error exporting explanation:", err)
+			return err
+		}
+	}
+	if candidatesRecord := listing.getCandidatesRecord(); ls.candidatesEncoder != nil && candidatesRecord != nil {
+		if err = ls.candidatesEncoder.Encode(candidatesRecord); err != nil {
+			fmt.Println("Error exporting candidates:", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// Close matches the last batch, drops the irregularly priced results and writes out the listings they unmatched,
+// and the details of the matched listings, in listing order, then closes the output files. The product results are
+// ready to be exported afterwards.
+func (ls *ListingStream) Close() (err error) {
+	defer func() {
+		ls.unmatchedFile.Close()
+		if ls.explanationsFile != nil {
+			ls.explanationsFile.Close()
+		}
+		if ls.candidatesFile != nil {
+			ls.candidatesFile.Close()
+		}
+		if ls.ambiguousFile != nil {
+			ls.ambiguousFile.Close()
+		}
+	}()
+	if err = ls.matchBatch(); err != nil {
+		return err
+	}
+	// the listings dropped by the price check are unmatched by it
+	ls.matcher.dropIrregularlyPricedResults()
+	for _, listing := range ls.matched {
+		if listing.match == nil {
+			err = ls.writeUnmatched(listing)
+		} else {
+			ls.outcomeCounts.add(listing)
+			err = ls.writeDetails(listing)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	ls.matched = nil
+	fmt.Println("Done streaming", ls.listingCount, "listings,", ls.unmatchedCount, "unmatched listings written to", ls.unmatchedFile.Name())
+	return nil
+}
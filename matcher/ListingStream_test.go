@@ -0,0 +1,150 @@
+package matcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// streamListings decodes the listings into the stream like JSONArchive does
+func streamListings(ls *ListingStream, listings []*Listing) (err error) {
+	data := &bytes.Buffer{}
+	jsonEncoder := json.NewEncoder(data)
+	for _, listing := range listings {
+		if err = jsonEncoder.Encode(listing); err != nil {
+			return err
+		}
+	}
+	decoder := json.NewDecoder(data)
+	for {
+		if err = ls.Decode(decoder); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return err
+		}
+	}
+}
+
+// newStreamTestListings returns more than two batches of listings, some unmatched and some dropped by the price check
+func newStreamTestListings() (listings []*Listing) {
+	titles := []string{"Sony DSC-W310", "Olympus Stylus", "Canon PowerShot SX210 IS", "Samsung TL240", "Canon PowerShot SX200 IS"}
+	for listingIndex := 0; listingIndex < 2*listingStreamBatchSize+100; listingIndex++ {
+		price := strconv.Itoa(100 + listingIndex%10)
+		if listingIndex%9 == 0 {
+			price = "999"
+		}
+		listings = append(listings, &Listing{Title: titles[listingIndex%len(titles)] + " #" + strconv.Itoa(listingIndex), Currency: "USD", Price: price})
+	}
+	return listings
+}
+
+// readLines returns the lines of a file
+func readLines(t *testing.T, filename string) []string {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestListingStreamMatchesLikeMatchListings(t *testing.T) {
+	directory := t.TempDir()
+	configure := func(config *Config) { config.Explain = true }
+	m := newTestMatcher(newTestCatalog(), configure)
+	listings := &Listings{}
+	for _, listing := range newStreamTestListings() {
+		listings.Add(listing)
+	}
+	m.MatchListings(listings)
+	if err := m.Products().ExportResults(filepath.Join(directory, "results.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := listings.ExportUnmatchedListings(filepath.Join(directory, "unmatched.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := listings.ExportExplanations(filepath.Join(directory, "explain.txt")); err != nil {
+		t.Fatal(err)
+	}
+	m = newTestMatcher(newTestCatalog(), configure)
+	ls, err := NewListingStream(m, filepath.Join(directory, "stream-unmatched.txt"), filepath.Join(directory, "stream-explain.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = streamListings(ls, newStreamTestListings()); err != nil {
+		t.Fatal(err)
+	}
+	if err = ls.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Products().ExportResults(filepath.Join(directory, "stream-results.txt")); err != nil {
+		t.Fatal(err)
+	}
+	results, streamResults := readLines(t, filepath.Join(directory, "results.txt")), readLines(t, filepath.Join(directory, "stream-results.txt"))
+	if strings.Join(results, "\n") != strings.Join(streamResults, "\n") {
+		t.Error("streamed results differ from the matched ones")
+	}
+	for _, fileName := range []string{"unmatched.txt", "explain.txt"} {
+		lines, streamLines := readLines(t, filepath.Join(directory, fileName)), readLines(t, filepath.Join(directory, "stream-"+fileName))
+		sort.Strings(lines)
+		sort.Strings(streamLines)
+		if strings.Join(lines, "\n") != strings.Join(streamLines, "\n") {
+			t.Errorf("streamed %s holds different records than the matched one", fileName)
+		}
+	}
+	// the explanations of the matched listings follow the unmatched ones in listing order
+	previousNumber := -1
+	for _, line := range readLines(t, filepath.Join(directory, "stream-explain.txt")) {
+		explanation := &Explanation{}
+		if err = json.Unmarshal([]byte(line), explanation); err != nil {
+			t.Fatal(err)
+		}
+		if explanation.Decision != DecisionMatched {
+			continue
+		}
+		number, _ := strconv.Atoi(explanation.Title[strings.LastIndex(explanation.Title, "#")+1:])
+		if number < previousNumber {
+			t.Fatalf("matched listing %d explained after listing %d", number, previousNumber)
+		}
+		previousNumber = number
+	}
+}
+
+func TestListingStreamWriteError(t *testing.T) {
+	m := newTestMatcher(newTestCatalog(), nil)
+	ls, err := NewListingStream(m, filepath.Join(t.TempDir(), "unmatched.txt"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the unmatched listings can't be written anymore
+	ls.unmatchedFile.Close()
+	listings := newStreamTestListings()[:listingStreamBatchSize]
+	if err = streamListings(ls, listings); err == nil {
+		t.Fatal("expected an error writing the unmatched listings")
+	}
+	ls.Close()
+	// the failed batch isn't matched again by Close
+	matchedCount := 0
+	for _, product := range m.Products().products {
+		matchedCount += len(product.Result().Listings)
+	}
+	if matchedCount > len(listings) {
+		t.Errorf("%d listings in the product results for %d listings streamed", matchedCount, len(listings))
+	}
+}
+
+func TestListingStreamRejectsListingIDF(t *testing.T) {
+	m := newTestMatcher(newTestCatalog(), func(config *Config) {
+		config.ScoringModel = ScoringModelIDF
+		config.ListingIDF = true
+	})
+	if _, err := NewListingStream(m, filepath.Join(t.TempDir(), "unmatched.txt"), ""); err == nil {
+		t.Error("expected an error streaming with listing idf weights")
+	}
+}
@@ -0,0 +1,451 @@
+package matcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Listing defines the fields found in the listings.txt json file
+type Listing struct {
+	Title                string `json:"title"`
+	Manufacturer         string `json:"manufacturer"`
+	Currency             string `json:"currency"`
+	Price                string `json:"price"`
+	Date                 string `json:"date,omitempty"` // optional listing date used to pick exchange rates
+	parsedPrice          float64
+	parsedCurrency       string // currency named by a symbol or code in the price
+	priceStatus          PriceStatus
+	usdPrice             float64
+	usdPriceRates        *CurrencyRates // the rates usdPrice was converted with, nil until it's converted
+	match                *Product
+	classification       ListingClass
+	classificationReason string
+	decision             string
+	priceRejection       *PriceRejection
+	explanation          *Explanation
+	candidates           []RankedCandidate // nil unless Config.TopCandidates is set
+	competingProducts    []*Product        // products that made the listing ambiguous
+}
+
+// ParsedPrice returns the listing's price parsed from it's Price field and how it was parsed
+func (l *Listing) ParsedPrice() (price float64, status PriceStatus) {
+	if l.priceStatus == PriceUnparsed {
+		l.parsedPrice, l.parsedCurrency, l.priceStatus = parsePrice(l.Price)
+	}
+	return l.parsedPrice, l.priceStatus
+}
+
+// GetCurrency returns the listing's currency, inferred from the price when the Currency field is empty
+func (l *Listing) GetCurrency() string {
+	if l.Currency != "" {
+		return l.Currency
+	}
+	l.ParsedPrice()
+	return l.parsedCurrency
+}
+
+// GetPrice return price of item in USD, converted with the exchange rates in effect at the listing's date
+func (l *Listing) GetPrice(rates *CurrencyRates, defaultPrice float64) float64 {
+	// the price is only converted once per rates so that unknown currencies are only counted once per listing,
+	// and converted again when the matcher's rates are replaced
+	if l.usdPriceRates != rates {
+		l.usdPriceRates = rates
+		l.usdPrice = -1
+		price, status := l.ParsedPrice()
+		if status != PriceValid && status != PriceRange {
+			fmt.Println("Price conversion error for listing", l.Title, ", price:", l.Price, ", status:", status)
+			return defaultPrice
+		}
+		date, err := parseDate(l.Date)
+		if err != nil {
+			fmt.Println("Date conversion error for listing", l.Title, err)
+		}
+		if usdPrice, found := rates.ToUSD(price, l.GetCurrency(), date); found {
+			l.usdPrice = usdPrice
+		}
+	}
+	if l.usdPrice < 0 {
+		return defaultPrice
+	}
+	return l.usdPrice
+}
+
+// MatchedProduct returns the product the listing was matched to, or nil if it is unmatched
+func (l *Listing) MatchedProduct() *Product {
+	return l.match
+}
+
+// Decision returns the outcome of matching the listing, one of the Decision constants
+func (l *Listing) Decision() string {
+	return l.decision
+}
+
+// recordMatch stores the match result in the listing and adds the listing to the matched product's results
+func (l *Listing) recordMatch(matchResult MatchResult) {
+	l.classification = matchResult.Classification
+	l.classificationReason = matchResult.ClassificationReason
+	l.decision = matchResult.Decision
+	l.explanation = matchResult.Explanation
+	l.candidates = matchResult.Candidates
+	l.competingProducts = matchResult.CompetingProducts
+	// accessories are kept out of the product's results
+	if matchResult.Product != nil && matchResult.Classification != Accessory {
+		l.match = matchResult.Product
+		matchResult.Product.result.Listings = append(matchResult.Product.result.Listings, l)
+		matchResult.Product.result.tokenOrderDifferences = append(matchResult.Product.result.tokenOrderDifferences, matchResult.TokenOrderDifference)
+		matchResult.Product.result.confidences = append(matchResult.Product.result.confidences, matchResult.Confidence)
+	}
+}
+
+// PriceRejection returns why the listing was dropped by the price check, nil if it wasn't
+func (l *Listing) PriceRejection() *PriceRejection {
+	return l.priceRejection
+}
+
+// rejectPrice unmatches a listing dropped by the price check
+func (l *Listing) rejectPrice(priceRejection *PriceRejection) {
+	l.match = nil
+	l.decision = DecisionPriceRejected
+	l.priceRejection = priceRejection
+	l.explanation.rejectPrice(priceRejection)
+}
+
+// Classification returns whether the listing is for the primary product, an accessory or a bundle, and the reason for it
+func (l *Listing) Classification() (ListingClass, string) {
+	return l.classification, l.classificationReason
+}
+
+// Listings struct to hold the listing data
+// implementes JSONDecoder
+type Listings struct {
+	FileName string
+	// ExcludeAmbiguous keeps the ambiguous listings out of ExportUnmatchedListings, for when they're exported separately
+	ExcludeAmbiguous      bool
+	listings              []*Listing
+	unmatchedProductCount int
+}
+
+// GetFileName used by JSONArchive util
+func (l *Listings) GetFileName() string {
+	if l.FileName != "" {
+		return l.FileName
+	}
+	return "listings.txt"
+}
+
+// Decode used by JSONArchive util
+func (l *Listings) Decode(decoder *json.Decoder) (err error) {
+	listing := &Listing{}
+	err = decoder.Decode(&listing)
+	if err == nil {
+		l.listings = append(l.listings, listing)
+	}
+	return
+}
+
+// Add appends a listing to the listings
+func (l *Listings) Add(listing *Listing) {
+	l.listings = append(l.listings, listing)
+}
+
+// GetListingCount returns the number of listings in the array
+func (l *Listings) GetListingCount() int {
+	return len(l.listings)
+}
+
+// isSubsetOf return true if possibleSubset is a subset of possibleSuperset
+func isSubsetOf(possibleSubset, possibleSuperset []int) bool {
+	if len(possibleSubset) >= len(possibleSuperset) {
+		return false
+	}
+	for _, subsetValue := range possibleSubset {
+		matchFound := false
+		for _, supersetValue := range possibleSuperset {
+			if subsetValue == supersetValue {
+				matchFound = true
+				break
+			}
+		}
+		if !matchFound {
+			return false
+		}
+	}
+	return true
+}
+
+// listingMatch holds the state used while looking for the product matching a listing
+type listingMatch struct {
+	listingTokens         []string
+	manufacturers         map[string]bool // canonical catalog manufacturers named in the listing's manufacturer field
+	listingDate           time.Time       // zero when the listing isn't dated
+	possibleMatches       []*Product
+	tokenOrderDifferences []int
+	confidences           []float64         // confidence in each possible match, only set when scorer isn't nil
+	scorer                *tokenScorer      // nil unless the idf scoring model is used
+	fuzzyTokens           []map[int]float64 // similarity of the loosely matched product tokens at each listing position
+	joinedTokens          []joinedToken     // joined model number forms of the listing tokens
+	explanation           *Explanation      // nil unless explanations are enabled
+}
+
+// findJoinedModelNumbers returns the products whose joined model number is a joined form of the listing tokens
+func (lm *listingMatch) findJoinedModelNumbers(pt *ProductTokens) (products []*Product) {
+	lm.joinedTokens = generateJoinedTokens(lm.listingTokens)
+	for _, joinedToken := range lm.joinedTokens {
+		products = append(products, pt.modelKeys[joinedToken.value]...)
+	}
+	return
+}
+
+// getListingTokensFor returns the listing tokens with the first joined form of the product's model number split
+// the way the product's model is, so a joined model number is equivalent to it's split sequence. originalPositions
+// maps the returned tokens to their position in the listing tokens, -1 for the split model tokens, and is nil when
+// the listing tokens are returned unchanged.
+func (lm *listingMatch) getListingTokensFor(product *Product) (listingTokens []string, originalPositions []int) {
+	for _, joinedToken := range lm.joinedTokens {
+		if joinedToken.value != product.modelKey {
+			continue
+		}
+		if strings.Join(lm.listingTokens[joinedToken.start:joinedToken.end], " ") == strings.Join(product.modelTokens, " ") {
+			break
+		}
+		listingTokens = append([]string{}, lm.listingTokens[:joinedToken.start]...)
+		listingTokens = append(listingTokens, product.modelTokens...)
+		listingTokens = append(listingTokens, lm.listingTokens[joinedToken.end:]...)
+		for position := range listingTokens {
+			switch {
+			case position < joinedToken.start:
+				originalPositions = append(originalPositions, position)
+			case position < joinedToken.start+len(product.modelTokens):
+				originalPositions = append(originalPositions, -1)
+			default:
+				originalPositions = append(originalPositions, position-len(product.modelTokens)+joinedToken.end-joinedToken.start)
+			}
+		}
+		return listingTokens, originalPositions
+	}
+	return lm.listingTokens, nil
+}
+
+// getExplainedPositions converts token positions in the tokens returned by getListingTokensFor back to positions
+// in the listing tokens, the split model tokens point into the joined form they were split from
+func (lm *listingMatch) getExplainedPositions(tokenPositions, originalPositions []int) []int {
+	if originalPositions == nil || lm.explanation == nil {
+		return tokenPositions
+	}
+	// the split model tokens start where the joined form starts, and are followed by the token after it
+	splitStart := slices.Index(originalPositions, -1)
+	splitEnd := splitStart
+	for splitEnd < len(originalPositions) && originalPositions[splitEnd] < 0 {
+		splitEnd++
+	}
+	joinedEnd := len(lm.listingTokens)
+	if splitEnd < len(originalPositions) {
+		joinedEnd = originalPositions[splitEnd]
+	}
+	explainedPositions := make([]int, len(tokenPositions))
+	for tokenIndex, position := range tokenPositions {
+		switch {
+		case position < 0:
+			explainedPositions[tokenIndex] = position
+		case originalPositions[position] >= 0:
+			explainedPositions[tokenIndex] = originalPositions[position]
+		case position == splitEnd-1:
+			explainedPositions[tokenIndex] = joinedEnd - 1
+		default:
+			explainedPositions[tokenIndex] = min(position, joinedEnd-1)
+		}
+	}
+	return explainedPositions
+}
+
+// removePossibleMatch removes the possible match at the index along with it's token order difference and confidence
+func (lm *listingMatch) removePossibleMatch(index int) {
+	lm.possibleMatches = append(lm.possibleMatches[:index], lm.possibleMatches[index+1:]...)
+	lm.tokenOrderDifferences = append(lm.tokenOrderDifferences[:index], lm.tokenOrderDifferences[index+1:]...)
+	lm.confidences = append(lm.confidences[:index], lm.confidences[index+1:]...)
+}
+
+// addPossibleMatch adds a match to a list of matches if it passes certains checks
+func (lm *listingMatch) addPossibleMatch(pt *ProductTokens, config *Config, possibleMatch *Product) {
+	possibleMatches := &lm.possibleMatches
+	tokenOrderDifferences := &lm.tokenOrderDifferences
+	listingTokens, originalPositions := lm.getListingTokensFor(possibleMatch)
+	// don't add the product if it's already in the possible matches
+	for _, existingMatch := range *possibleMatches {
+		if existingMatch == possibleMatch {
+			return
+		}
+	}
+	// make sure that all of the tokens are present, and calculate the token order difference value
+	tokenOrderDifference := 0
+	expectedNextTokenPosition := 0
+	// veto or penalize the match if the listing's manufacturer field names a different manufacturer
+	manufacturerAgreement := getManufacturerAgreement(lm.manufacturers, possibleMatch)
+	if manufacturerAgreement == manufacturerConflicts {
+		if config.ManufacturerConflictPenalty < 0 {
+			lm.explanation.explainCandidate(possibleMatch, nil, tokenOrderDifference, "manufacturer field conflicts")
+			return
+		}
+		tokenOrderDifference += config.ManufacturerConflictPenalty
+	}
+	// veto or penalize the match if the product was announced after the listing's date
+	if config.AnachronismPenalty != 0 && possibleMatch.isAnnouncedAfter(lm.listingDate) {
+		if config.AnachronismPenalty < 0 {
+			lm.explanation.explainCandidate(possibleMatch, nil, tokenOrderDifference, "announced after the listing date")
+			return
+		}
+		tokenOrderDifference += config.AnachronismPenalty
+	}
+	missingManufacturerTokens := possibleMatch.manufacturerTokenCount == 0
+	missingFamilyTokens := possibleMatch.familyTokenCount == 0
+	tokenPositions := make([]int, len(possibleMatch.tokenList))
+	for tokenIndex := range tokenPositions {
+		tokenPositions[tokenIndex] = -1
+	}
+	for tokenIndex, tokenObjectIndex := range possibleMatch.tokenList {
+		requiredToken := &pt.tokens[tokenObjectIndex]
+		tokenFound := false
+		for distanceFromExpectedPosition := 0; distanceFromExpectedPosition <= expectedNextTokenPosition || distanceFromExpectedPosition+expectedNextTokenPosition < len(listingTokens); distanceFromExpectedPosition++ {
+			if tokenIndex > possibleMatch.manufacturerTokenCount+possibleMatch.familyTokenCount && distanceFromExpectedPosition > 0 {
+				break // don't match out of order model numbers
+			}
+			if distanceFromExpectedPosition+expectedNextTokenPosition < len(listingTokens) {
+				similarity := lm.getTokenSimilarity(listingTokens, originalPositions, expectedNextTokenPosition+distanceFromExpectedPosition, tokenObjectIndex, requiredToken)
+				if similarity > 0 {
+					if distanceFromExpectedPosition <= 2 ||
+						tokenIndex < possibleMatch.manufacturerTokenCount ||
+						tokenIndex >= possibleMatch.manufacturerTokenCount+possibleMatch.familyTokenCount {
+						tokenFound = true
+						tokenOrderDifference += distanceFromExpectedPosition + getFuzzyTokenPenalty(similarity, config)
+						tokenPositions[tokenIndex] = expectedNextTokenPosition + distanceFromExpectedPosition
+						expectedNextTokenPosition = expectedNextTokenPosition + distanceFromExpectedPosition + 1
+						break
+					}
+				}
+			}
+			if distanceFromExpectedPosition+1 < expectedNextTokenPosition && distanceFromExpectedPosition > 0 {
+				similarity := lm.getTokenSimilarity(listingTokens, originalPositions, expectedNextTokenPosition-1-distanceFromExpectedPosition, tokenObjectIndex, requiredToken)
+				if similarity > 0 {
+					tokenFound = true
+					tokenOrderDifference += distanceFromExpectedPosition + getFuzzyTokenPenalty(similarity, config)
+					tokenPositions[tokenIndex] = expectedNextTokenPosition - 1 - distanceFromExpectedPosition
+					expectedNextTokenPosition = expectedNextTokenPosition - distanceFromExpectedPosition
+					break
+				}
+			}
+		}
+		// ignore a missing manufacturer or family token, but not both
+		if !tokenFound {
+			if tokenIndex < possibleMatch.manufacturerTokenCount {
+				// the manufacturer field fills in for a manufacturer missing from the title
+				if manufacturerAgreement == manufacturerAgrees {
+					continue
+				}
+				if !missingFamilyTokens {
+					if !missingManufacturerTokens {
+						missingManufacturerTokens = true
+						tokenOrderDifference += 2
+					}
+					continue
+				}
+			} else if tokenIndex < possibleMatch.manufacturerTokenCount+possibleMatch.familyTokenCount {
+				if !missingManufacturerTokens {
+					if !missingFamilyTokens {
+						missingFamilyTokens = true
+						tokenOrderDifference += 2
+					}
+					continue
+				}
+			}
+			lm.explanation.explainCandidate(possibleMatch, lm.getExplainedPositions(tokenPositions, originalPositions), tokenOrderDifference, "missing token "+requiredToken.value)
+			return
+		}
+	}
+	// eliminate subsets of this product, and eliminate this product if it's a subset of an existing product.
+	// existingIndex only moves on when nothing was removed, so the match shifted into it's place is checked too.
+	for existingIndex := 0; existingIndex < len(*possibleMatches); {
+		existingMatch := (*possibleMatches)[existingIndex]
+		// eliminate this match if it's a subset of a previous match
+		if isSubsetOf(possibleMatch.tokenList, existingMatch.tokenList) {
+			lm.explanation.explainCandidate(possibleMatch, lm.getExplainedPositions(tokenPositions, originalPositions), tokenOrderDifference, "subset of "+existingMatch.ProductName)
+			return
+		}
+		// eliminate previous matches that are subsets of this match
+		if isSubsetOf(existingMatch.tokenList, possibleMatch.tokenList) &&
+			tokenOrderDifference <= (*tokenOrderDifferences)[existingIndex] {
+			lm.explanation.eliminateCandidate(existingMatch, "eliminated by superset "+possibleMatch.ProductName)
+			lm.removePossibleMatch(existingIndex)
+			continue
+		}
+		existingIndex++
+	}
+	// add the match and store the token order difference value
+	*possibleMatches = append(*possibleMatches, possibleMatch)
+	*tokenOrderDifferences = append(*tokenOrderDifferences, tokenOrderDifference)
+	confidence := lm.scorer.getConfidence(possibleMatch, tokenPositions, manufacturerAgreement, tokenOrderDifference, config)
+	lm.confidences = append(lm.confidences, confidence)
+	lm.explanation.explainCandidate(possibleMatch, lm.getExplainedPositions(tokenPositions, originalPositions), tokenOrderDifference, "")
+	lm.explanation.scoreCandidate(possibleMatch, confidence)
+}
+
+// mapToProducts associates listings with products using the given matcher
+func (l *Listings) mapToProducts(m *Matcher) {
+	matchResults := m.matchAll(l.listings)
+	// record the results in listing order so that they don't depend on the number of workers
+	for listingIndex, listing := range l.listings {
+		listing.recordMatch(matchResults[listingIndex])
+	} // end of iterating through listings
+}
+
+// ExportUnmatchedListings export a list of unmatched listings to the given filename
+func (l *Listings) ExportUnmatchedListings(filename string) (err error) {
+	unmatchedListingsFile, err := os.Create(filename)
+	if err != nil {
+		fmt.Println("Error creating file for unmatched listings:", err)
+		return err
+	}
+	defer unmatchedListingsFile.Close()
+	jsonEncoder := json.NewEncoder(unmatchedListingsFile)
+	l.unmatchedProductCount = 0
+	for _, listing := range l.listings {
+		if listing.match == nil && !(l.ExcludeAmbiguous && listing.Outcome() == OutcomeAmbiguous) {
+			l.unmatchedProductCount++
+			err = jsonEncoder.Encode(listing)
+			if err != nil {
+				fmt.Println("Error exporting unmatched listings to file", filename, ":", err)
+				return err
+			}
+		}
+	}
+	fmt.Println("Done writing", l.unmatchedProductCount, "unmatched listings to", filename)
+	return nil
+}
+
+// ExportExplanations export the explanation of every listing's match, one JSON object per line, to the given filename
+func (l *Listings) ExportExplanations(filename string) (err error) {
+	explanationsFile, err := os.Create(filename)
+	if err != nil {
+		fmt.Println("Error creating file for explanations:", err)
+		return err
+	}
+	defer explanationsFile.Close()
+	jsonEncoder := json.NewEncoder(explanationsFile)
+	explanationCount := 0
+	for _, listing := range l.listings {
+		if listing.explanation == nil {
+			continue
+		}
+		explanationCount++
+		err = jsonEncoder.Encode(listing.explanation)
+		if err != nil {
+			fmt.Println("Error exporting explanations to file", filename, ":", err)
+			return err
+		}
+	}
+	fmt.Println("Done writing", explanationCount, "listing explanations to", filename)
+	return nil
+}
@@ -0,0 +1,59 @@
+package matcher
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJoinedModelExplanationPositions(t *testing.T) {
+	m := newTestMatcher([]*Product{
+		{ProductName: "Sony_DSCW310", Manufacturer: "Sony", Model: "DSCW310"},
+	}, func(config *Config) {
+		config.Explain = true
+		config.JoinModelNumbers = true
+	})
+	matchResult := m.Match(&Listing{Title: "Sony DSC W310 camera"})
+	if matchResult.Product == nil {
+		t.Fatalf("listing didn't match, decision %q", matchResult.Decision)
+	}
+	explanation := matchResult.Explanation
+	if len(explanation.Candidates) != 1 {
+		t.Fatalf("expected one candidate, found %d", len(explanation.Candidates))
+	}
+	// the "dscw" and "310" model tokens point into the "dsc w 310" listing tokens they were joined from
+	if positions := explanation.Candidates[0].TokenPositions; !reflect.DeepEqual(positions, []int{0, 1, 3}) {
+		t.Errorf("token positions %v in %q, expected [0 1 3]", positions, explanation.ListingTokens)
+	}
+}
+
+func TestSupersetEliminatesEverySubset(t *testing.T) {
+	m := newTestMatcher([]*Product{
+		{ProductName: "Canon_EOS_7D", Manufacturer: "Canon", Family: "EOS", Model: "7D"},
+		{ProductName: "Canon_EOS_Mark_II", Manufacturer: "Canon", Family: "EOS", Model: "Mark II"},
+		{ProductName: "Canon_EOS_7D_Mark_II", Manufacturer: "Canon", Family: "EOS", Model: "7D Mark II"},
+	}, func(config *Config) { config.Explain = true })
+	for _, scoringModel := range []string{ScoringModelTokenOrder, ScoringModelIDF} {
+		m.config.ScoringModel = scoringModel
+		matchResult := m.Match(&Listing{Title: "Canon EOS 7D Mark II"})
+		if matchResult.Product == nil || matchResult.Product.ProductName != "Canon_EOS_7D_Mark_II" {
+			t.Errorf("%s scoring didn't match the superset, decision %q", scoringModel, matchResult.Decision)
+		}
+		for _, candidate := range matchResult.Explanation.Candidates {
+			if candidate.ProductName != "Canon_EOS_7D_Mark_II" && candidate.Eliminated == "" {
+				t.Errorf("%s scoring left %s %q", scoringModel, candidate.ProductName, candidate.Eliminated)
+			}
+		}
+	}
+}
+
+func TestJoinModelNumbersIsOptIn(t *testing.T) {
+	products := []*Product{{ProductName: "Sony_DSCW310", Manufacturer: "Sony", Model: "DSCW310"}}
+	listing := &Listing{Title: "Sony DSC-W310 camera"}
+	if matchResult := newTestMatcher(products, nil).Match(listing); matchResult.Product != nil {
+		t.Errorf("%q matched without JoinModelNumbers", listing.Title)
+	}
+	m := newTestMatcher(products, func(config *Config) { config.JoinModelNumbers = true })
+	if matchResult := m.Match(listing); matchResult.Product == nil {
+		t.Errorf("%q didn't match with JoinModelNumbers, decision %q", listing.Title, matchResult.Decision)
+	}
+}
@@ -0,0 +1,85 @@
+package matcher
+
+import "strings"
+
+// manufacturerAliases maps alternate spellings of a manufacturer, as tokens joined by spaces, to it's canonical spelling
+var manufacturerAliases = map[string]string{
+	"fuji":             "fujifilm",
+	"fuji film":        "fujifilm",
+	"hewlett packard":  "hp",
+	"konica":           "konica minolta",
+	"minolta":          "konica minolta",
+	"general electric": "ge",
+}
+
+// manufacturer agreement values returned by getManufacturerAgreement
+const (
+	manufacturerUnknown = iota
+	manufacturerAgrees
+	manufacturerConflicts
+)
+
+// getCanonicalManufacturer returns the canonical manufacturer name for the given tokens
+func getCanonicalManufacturer(tokens []string) string {
+	name := strings.Join(tokens, " ")
+	if alias, found := manufacturerAliases[name]; found {
+		return alias
+	}
+	return name
+}
+
+// manufacturerIndex holds the canonical names of the manufacturers found in the product catalog
+type manufacturerIndex struct {
+	names         map[string]bool
+	maxTokenCount int
+}
+
+// add adds a product's manufacturer tokens to the index and returns it's canonical name
+func (mi *manufacturerIndex) add(tokens []string) (name string) {
+	if len(tokens) == 0 {
+		return ""
+	}
+	if mi.names == nil {
+		mi.names = map[string]bool{}
+	}
+	name = getCanonicalManufacturer(tokens)
+	mi.names[name] = true
+	if len(tokens) > mi.maxTokenCount {
+		mi.maxTokenCount = len(tokens)
+	}
+	return
+}
+
+// find returns the catalog manufacturers named in the tokens, e.g. "canon" for "Canon Canada"
+func (mi *manufacturerIndex) find(tokens []string) (names map[string]bool) {
+	// aliases can be longer than any catalog manufacturer name
+	maxTokenCount := mi.maxTokenCount
+	for alias := range manufacturerAliases {
+		if aliasTokenCount := strings.Count(alias, " ") + 1; aliasTokenCount > maxTokenCount {
+			maxTokenCount = aliasTokenCount
+		}
+	}
+	for start := range tokens {
+		for end := start + 1; end <= len(tokens) && end-start <= maxTokenCount; end++ {
+			name := getCanonicalManufacturer(tokens[start:end])
+			if mi.names[name] {
+				if names == nil {
+					names = map[string]bool{}
+				}
+				names[name] = true
+			}
+		}
+	}
+	return
+}
+
+// getManufacturerAgreement compares the manufacturers named by a listing with the product's manufacturer
+func getManufacturerAgreement(listingManufacturers map[string]bool, product *Product) int {
+	if len(listingManufacturers) == 0 || product.manufacturerName == "" {
+		return manufacturerUnknown
+	}
+	if listingManufacturers[product.manufacturerName] {
+		return manufacturerAgrees
+	}
+	return manufacturerConflicts
+}
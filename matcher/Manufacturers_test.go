@@ -0,0 +1,54 @@
+package matcher
+
+import "testing"
+
+func TestManufacturerField(t *testing.T) {
+	products := []*Product{
+		{ProductName: "Canon_PowerShot_SX210_IS", Manufacturer: "Canon", Family: "PowerShot", Model: "SX210 IS"},
+		{ProductName: "Kodak_EasyShare_Z981", Manufacturer: "Kodak", Family: "EasyShare", Model: "Z981"},
+		{ProductName: "Fujifilm_FinePix_S2500HD", Manufacturer: "Fujifilm", Family: "FinePix", Model: "S2500HD"},
+	}
+	tests := []struct {
+		penalty              int
+		title                string
+		manufacturer         string
+		productName          string
+		tokenOrderDifference int
+	}{
+		// the field is ignored by default
+		{0, "Battery for Canon PowerShot SX210 IS", "Kodak", "Canon_PowerShot_SX210_IS", 2},
+		{0, "PowerShot SX210 IS", "Canon Canada", "Canon_PowerShot_SX210_IS", 2},
+		// a conflicting manufacturer vetoes or penalizes the match
+		{-1, "Battery for Canon PowerShot SX210 IS", "Kodak", "", 0},
+		{3, "Battery for Canon PowerShot SX210 IS", "Kodak", "Canon_PowerShot_SX210_IS", 5},
+		// manufacturers that aren't in the catalog can't conflict
+		{-1, "Battery for Canon PowerShot SX210 IS", "Duracell", "Canon_PowerShot_SX210_IS", 2},
+		// an agreeing manufacturer fills in for the one missing from the title, whatever it's spelling
+		{-1, "PowerShot SX210 IS", "Canon Canada", "Canon_PowerShot_SX210_IS", 0},
+		{-1, "FinePix S2500HD", "Fuji", "Fujifilm_FinePix_S2500HD", 0},
+		{-1, "Fuji FinePix S2500HD", "Fuji Film", "Fujifilm_FinePix_S2500HD", 1},
+	}
+	for _, test := range tests {
+		m := newTestMatcher(products, func(config *Config) { config.ManufacturerConflictPenalty = test.penalty })
+		matchResult := m.Match(&Listing{Title: test.title, Manufacturer: test.manufacturer})
+		productName := ""
+		if matchResult.Product != nil {
+			productName = matchResult.Product.ProductName
+		}
+		if productName != test.productName || matchResult.TokenOrderDifference != test.tokenOrderDifference {
+			t.Errorf("%q by %q with penalty %d matched %q with token order difference %d, expected %q with %d", test.title, test.manufacturer,
+				test.penalty, productName, matchResult.TokenOrderDifference, test.productName, test.tokenOrderDifference)
+		}
+	}
+}
+
+func TestGetCanonicalManufacturer(t *testing.T) {
+	tests := map[string]string{
+		"fuji": "fujifilm", "fuji film": "fujifilm", "fujifilm": "fujifilm", "minolta": "konica minolta", "canon": "canon",
+	}
+	for name, canonicalName := range tests {
+		if found := getCanonicalManufacturer(generateTokensFromString(name)); found != canonicalName {
+			t.Errorf("getCanonicalManufacturer(%q) = %q, expected %q", name, found, canonicalName)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package matcher
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Decision values telling how a listing's match was decided
+const (
+	DecisionMatched        = "matched"
+	DecisionNoCandidates   = "no candidates"
+	DecisionAboveThreshold = "above threshold"
+	DecisionAmbiguous      = "ambiguous"
+	DecisionLowConfidence  = "low confidence"
+	DecisionAccessory      = "accessory"
+	DecisionPriceRejected  = "price rejected"
+)
+
+// MatchResult holds the outcome of matching a single listing
+type MatchResult struct {
+	Product              *Product
+	TokenOrderDifference int
+	Confidence           float64 // only set by the idf scoring model
+	Decision             string
+	Classification       ListingClass
+	ClassificationReason string
+	Explanation          *Explanation      // only set when Config.Explain is true
+	Candidates           []RankedCandidate // the best Config.TopCandidates candidates, empty when it's 0
+	CompetingProducts    []*Product        // the products matching equally well when the decision is ambiguous
+}
+
+// Matcher matches listings to the products of a catalog
+type Matcher struct {
+	products      *Products
+	productTokens *ProductTokens
+	config        Config
+	currencyRates *CurrencyRates
+	tokenizers    *FieldTokenizers
+	// listing token frequencies for Config.ListingIDF, see countListingTokens
+	listingFrequencies map[string]int
+	listingCount       int
+}
+
+// NewMatcher returns a Matcher with the product tokens generated from the given catalog
+func NewMatcher(products *Products, config Config) *Matcher {
+	return NewMatcherWithTokenizers(products, config, DefaultFieldTokenizers())
+}
+
+// NewMatcherWithTokenizers returns a Matcher tokenizing the product and listing fields with the given tokenizers
+func NewMatcherWithTokenizers(products *Products, config Config, tokenizers *FieldTokenizers) *Matcher {
+	return &Matcher{
+		products:      products,
+		productTokens: products.GetTokens(tokenizers),
+		config:        config,
+		currencyRates: DefaultCurrencyRates(),
+		tokenizers:    tokenizers,
+	}
+}
+
+// SetCurrencyRates replaces the default exchange rates used to compare listing prices
+func (m *Matcher) SetCurrencyRates(currencyRates *CurrencyRates) {
+	m.currencyRates = currencyRates
+}
+
+// CurrencyRates returns the exchange rates used to compare listing prices
+func (m *Matcher) CurrencyRates() *CurrencyRates {
+	return m.currencyRates
+}
+
+// Products returns the product catalog used by the matcher
+func (m *Matcher) Products() *Products {
+	return m.products
+}
+
+// resetResults clears the listings matched to the products and the listing token frequencies, so the matcher can
+// match another set of listings from scratch
+func (m *Matcher) resetResults() {
+	for _, product := range m.products.products {
+		product.result = Result{ProductName: product.ProductName, Listings: []*Listing{}}
+	}
+	m.listingFrequencies = nil
+	m.listingCount = 0
+}
+
+// RemoveProduct withdraws a product from the catalog so listings no longer match it, returning false if it wasn't in the catalog.
+// Not safe to call while listings are being matched.
+func (m *Matcher) RemoveProduct(product *Product) bool {
+	if !m.products.remove(product) {
+		return false
+	}
+	m.productTokens.RemoveProduct(product)
+	return true
+}
+
+// Vocabulary returns the sorted product token values listings are matched against
+func (m *Matcher) Vocabulary() []string {
+	return m.productTokens.GetVocabulary()
+}
+
+// Match finds the product that best matches the listing. The product's results are not modified,
+// so Match can be called from concurrent goroutines.
+func (m *Matcher) Match(listing *Listing) (matchResult MatchResult) {
+	pt := m.productTokens
+	// get a list of matching tokens and possible matches
+	lm := &listingMatch{
+		listingTokens: m.tokenizers.Title.Tokenize(listing.Title),
+		scorer:        m.getTokenScorer(),
+	}
+	if m.config.ManufacturerConflictPenalty != 0 {
+		lm.manufacturers = pt.manufacturers.find(m.tokenizers.Manufacturer.Tokenize(listing.Manufacturer))
+	}
+	// date errors are reported when the listing's price is converted
+	lm.listingDate, _ = parseDate(listing.Date)
+	// split the listing tokens made of several product tokens before anything refers to their positions
+	if m.config.FuzzyTokens {
+		lm.splitListingTokens(pt, &m.config)
+	}
+	// the candidates are ranked from the explanation, which is only returned when it's enabled
+	if m.config.Explain || m.config.TopCandidates > 0 {
+		lm.explanation = newExplanation(listing, lm.listingTokens)
+	}
+	// find the loosely matching tokens first so they count for every candidate
+	var fuzzyMatches, joinedModelMatches []*Product
+	if m.config.FuzzyTokens {
+		fuzzyMatches = lm.findFuzzyTokens(pt, &m.config)
+	}
+	if m.config.JoinModelNumbers {
+		joinedModelMatches = lm.findJoinedModelNumbers(pt)
+	}
+	for _, listingToken := range lm.listingTokens {
+		matchingToken := pt.getMatchingToken(listingToken)
+		if matchingToken == nil {
+			continue
+		}
+		for _, matchingProduct := range matchingToken.products {
+			lm.addPossibleMatch(pt, &m.config, matchingProduct)
+		}
+	}
+	for _, matchingProduct := range append(fuzzyMatches, joinedModelMatches...) {
+		lm.addPossibleMatch(pt, &m.config, matchingProduct)
+	}
+	possibleMatches := lm.possibleMatches
+	tokenOrderDifferences := lm.tokenOrderDifferences
+	// eliminate a match with multiple products with tokenOrderDifferences that are close in value
+	// set the match of the token order difference is below the threshhold
+	var matchedProduct *Product
+	bestTokenOrderDifference := m.config.MaxTokenOrderDifference
+	var tokenOrderDifference int
+	matchResult.Decision = DecisionNoCandidates
+	if len(possibleMatches) > 0 {
+		matchResult.Decision = DecisionAboveThreshold
+	}
+	if lm.scorer != nil {
+		matchedProduct, bestTokenOrderDifference, matchResult.Confidence, matchResult.Decision, matchResult.CompetingProducts = lm.decideByConfidence(&m.config)
+		possibleMatches = nil
+	}
+	for possibleIndex, possibleProduct := range possibleMatches {
+		if possibleProduct != nil {
+			tokenOrderDifference = tokenOrderDifferences[possibleIndex]
+			if matchedProduct != nil {
+				if tokenOrderDifference*2 < bestTokenOrderDifference {
+					lm.explanation.decideCandidate(matchedProduct, "replaced by "+possibleProduct.ProductName)
+					bestTokenOrderDifference = tokenOrderDifference
+					matchedProduct = possibleProduct
+					continue
+				}
+				if tokenOrderDifference < bestTokenOrderDifference*2 {
+					if m.config.PreferRecentProducts {
+						newestProduct := getNewestProduct([]*Product{matchedProduct, possibleProduct})
+						if newestProduct == matchedProduct && newestProduct != nil {
+							lm.explanation.decideCandidate(possibleProduct, "announced before "+matchedProduct.ProductName)
+							continue
+						}
+						if newestProduct == possibleProduct && tokenOrderDifference <= m.config.TokenOrderDifferenceAllowance+len(possibleProduct.tokenList) {
+							lm.explanation.decideCandidate(matchedProduct, "announced before "+possibleProduct.ProductName)
+							bestTokenOrderDifference = tokenOrderDifference
+							matchedProduct = possibleProduct
+							continue
+						}
+					}
+					lm.explanation.decideCandidate(matchedProduct, "ambiguous with "+possibleProduct.ProductName)
+					lm.explanation.decideCandidate(possibleProduct, "ambiguous with "+matchedProduct.ProductName)
+					matchResult.Decision = DecisionAmbiguous
+					matchResult.CompetingProducts = []*Product{matchedProduct, possibleProduct}
+					matchedProduct = nil
+					break
+				}
+				lm.explanation.decideCandidate(possibleProduct, "worse than "+matchedProduct.ProductName)
+				continue
+			}
+			if tokenOrderDifference > m.config.TokenOrderDifferenceAllowance+len(possibleProduct.tokenList) {
+				lm.explanation.decideCandidate(possibleProduct, "above threshold")
+				continue
+			}
+			bestTokenOrderDifference = tokenOrderDifference
+			matchedProduct = possibleProduct
+		}
+	}
+	if matchedProduct != nil {
+		lm.explanation.decideCandidate(matchedProduct, "matched")
+		matchResult.Decision = DecisionMatched
+		matchResult.Product = matchedProduct
+		matchResult.TokenOrderDifference = bestTokenOrderDifference
+	}
+	// tell apart accessories and bundles from the product itself
+	if m.config.DetectAccessories {
+		productStart := getFirstProductTokenPosition(pt, lm.listingTokens, matchedProduct)
+		matchResult.Classification, matchResult.ClassificationReason = classifyListing(lm.listingTokens, productStart, getPlusPositions(m.tokenizers.Title, listing.Title))
+		if matchedProduct != nil && matchResult.Classification == Accessory {
+			matchResult.Decision = DecisionAccessory
+		}
+	}
+	if lm.explanation != nil {
+		lm.explanation.Decision = matchResult.Decision
+		lm.explanation.Confidence = matchResult.Confidence
+		if matchedProduct != nil {
+			lm.explanation.MatchedProduct = matchedProduct.ProductName
+		}
+		lm.explanation.Classification = matchResult.Classification.String()
+		lm.explanation.ClassificationReason = matchResult.ClassificationReason
+		if m.config.TopCandidates > 0 {
+			matchResult.Candidates = lm.explanation.getTopCandidates(m.config.TopCandidates)
+		}
+		if m.config.Explain {
+			matchResult.Explanation = lm.explanation
+		}
+	}
+	return
+}
+
+// matchAll matches each listing using a pool of Config.Workers goroutines, the results are in the same order as the listings
+func (m *Matcher) matchAll(listings []*Listing) (matchResults []MatchResult) {
+	matchResults = make([]MatchResult, len(listings))
+	workerCount := m.config.Workers
+	if workerCount <= 0 {
+		workerCount = runtime.NumCPU()
+	}
+	if workerCount == 1 {
+		for listingIndex, listing := range listings {
+			matchResults[listingIndex] = m.Match(listing)
+		}
+		return
+	}
+	listingIndexes := make(chan int, workerCount)
+	var waitGroup sync.WaitGroup
+	for worker := 0; worker < workerCount; worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for listingIndex := range listingIndexes {
+				matchResults[listingIndex] = m.Match(listings[listingIndex])
+			}
+		}()
+	}
+	for listingIndex := range listings {
+		listingIndexes <- listingIndex
+	}
+	close(listingIndexes)
+	waitGroup.Wait()
+	return
+}
+
+// MatchListings maps the listings to the catalog's products and drops irregularly priced results
+func (m *Matcher) MatchListings(listings *Listings) {
+	// map listings to signatures
+	m.countListingTokens(listings.listings)
+	listings.mapToProducts(m)
+	// weed out price abberations
+	m.dropIrregularlyPricedResults()
+}
+
+// dropIrregularlyPricedResults drops the product results with inconsistent prices and returns the listings it unmatched
+func (m *Matcher) dropIrregularlyPricedResults() (droppedListings []*Listing) {
+	droppedListings = m.products.dropIrregularlyPricedResults(&m.config, m.currencyRates)
+	m.currencyRates.printUnknownCurrencies()
+	if m.config.PriceStatistics {
+		m.products.setPriceStats(m.currencyRates)
+	}
+	return
+}
@@ -0,0 +1,145 @@
+package matcher
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// newTestCatalog returns the products used by the matcher tests
+func newTestCatalog() []*Product {
+	return []*Product{
+		{ProductName: "Sony_Cyber-shot_DSC-W310", Manufacturer: "Sony", Family: "Cyber-shot", Model: "DSC-W310"},
+		{ProductName: "Canon_PowerShot_SX210_IS", Manufacturer: "Canon", Family: "PowerShot", Model: "SX210 IS"},
+		{ProductName: "Canon_PowerShot_SX200_IS", Manufacturer: "Canon", Family: "PowerShot", Model: "SX200 IS"},
+		{ProductName: "Samsung_TL240", Manufacturer: "Samsung", Model: "TL240"},
+	}
+}
+
+func TestMatch(t *testing.T) {
+	m := newTestMatcher(newTestCatalog(), nil)
+	tests := []struct {
+		title       string
+		productName string
+		decision    string
+	}{
+		{"Sony DSC-W310 12.1MP Digital Camera", "Sony_Cyber-shot_DSC-W310", DecisionMatched},
+		{"Canon PowerShot SX210 IS 14.1 MP Digital Camera", "Canon_PowerShot_SX210_IS", DecisionMatched},
+		{"Canon PowerShot SX200 IS", "Canon_PowerShot_SX200_IS", DecisionMatched},
+		{"Samsung TL240 14 MP", "Samsung_TL240", DecisionMatched},
+		{"Olympus Stylus Tough 6000", "", DecisionNoCandidates},
+		{"Canon PowerShot SX300 IS", "", DecisionNoCandidates},
+	}
+	for _, test := range tests {
+		matchResult := m.Match(&Listing{Title: test.title})
+		productName := ""
+		if matchResult.Product != nil {
+			productName = matchResult.Product.ProductName
+		}
+		if productName != test.productName || matchResult.Decision != test.decision {
+			t.Errorf("%q matched %q with decision %q, expected %q with decision %q", test.title, productName, matchResult.Decision, test.productName, test.decision)
+		}
+	}
+	// Match leaves the product results alone
+	for _, product := range m.Products().products {
+		if len(product.Result().Listings) != 0 {
+			t.Errorf("Match added listings to the results of %s", product.ProductName)
+		}
+	}
+}
+
+func TestMatchListings(t *testing.T) {
+	m := newTestMatcher(newTestCatalog(), nil)
+	listings := &Listings{}
+	titles := []string{"Canon PowerShot SX210 IS", "Olympus Stylus", "Canon PowerShot SX210 IS black", "Sony DSC-W310"}
+	for _, title := range titles {
+		listings.Add(&Listing{Title: title, Currency: "USD", Price: "199.99"})
+	}
+	m.MatchListings(listings)
+	expectedProducts := []string{"Canon_PowerShot_SX210_IS", "", "Canon_PowerShot_SX210_IS", "Sony_Cyber-shot_DSC-W310"}
+	for listingIndex, listing := range listings.listings {
+		productName := ""
+		if listing.MatchedProduct() != nil {
+			productName = listing.MatchedProduct().ProductName
+		}
+		if productName != expectedProducts[listingIndex] {
+			t.Errorf("%q matched %q, expected %q", listing.Title, productName, expectedProducts[listingIndex])
+		}
+	}
+	// the product results hold the matched listings in listing order
+	result := m.Products().products[1].Result()
+	if len(result.Listings) != 2 || result.Listings[0] != listings.listings[0] || result.Listings[1] != listings.listings[2] {
+		t.Errorf("SX210 results hold %d listings, expected the 1st and 3rd listings", len(result.Listings))
+	}
+}
+
+// matchWithWorkers matches the listings generated from the titles with the given number of workers and returns the
+// results, unmatched listings and explanations files it wrote
+func matchWithWorkers(t *testing.T, titles []string, workers int, configure func(config *Config)) (files [3][]byte) {
+	m := newTestMatcher(newTestCatalog(), func(config *Config) {
+		config.Explain = true
+		config.Workers = workers
+		if configure != nil {
+			configure(config)
+		}
+	})
+	listings := &Listings{}
+	for listingIndex := 0; listingIndex < 500; listingIndex++ {
+		// every 7th listing is priced far from the others, so the price check drops some
+		price := strconv.Itoa(100 + listingIndex%13)
+		if listingIndex%7 == 0 {
+			price = strconv.Itoa(1000 + listingIndex)
+		}
+		listings.Add(&Listing{Title: titles[listingIndex%len(titles)], Currency: "USD", Price: price})
+	}
+	m.MatchListings(listings)
+	directory := t.TempDir()
+	fileNames := [3]string{filepath.Join(directory, "results.txt"), filepath.Join(directory, "unmatched.txt"), filepath.Join(directory, "explain.txt")}
+	exports := [3]func(string) error{m.Products().ExportResults, listings.ExportUnmatchedListings, listings.ExportExplanations}
+	for fileIndex, fileName := range fileNames {
+		if err := exports[fileIndex](fileName); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[fileIndex] = data
+	}
+	return files
+}
+
+// run with -race to also check the workers don't share unsynchronized state
+func TestWorkersDontChangeResults(t *testing.T) {
+	titles := []string{
+		"Sony DSC-W310 12.1MP Digital Camera", "Sony Cyber-shot DSCW310", "Canon PowerShot SX210 IS 14.1 MP",
+		"Canon PowerShot SX200 IS", "Canon PowerShot SX210 IS / SX200 IS", "Samsung TL240 14 MP", "Olympus Stylus Tough 6000",
+		"Battery for Canon PowerShot SX210 IS", "Canon Powershoot SX210 IS", "Samsung TL 240 + 8GB SD card",
+	}
+	configurations := map[string]func(config *Config){
+		"default": nil,
+		"every option": func(config *Config) {
+			config.ScoringModel = ScoringModelIDF
+			config.ListingIDF = true
+			config.FuzzyTokens = true
+			config.JoinModelNumbers = true
+			config.DetectAccessories = true
+			config.TopCandidates = 3
+			config.PriceOutlierStrategy = PriceOutlierStrategyMAD
+		},
+	}
+	fileKinds := [3]string{"results", "unmatched listings", "explanations"}
+	for name, configure := range configurations {
+		expected := matchWithWorkers(t, titles, 1, configure)
+		for _, workers := range []int{2, 8} {
+			files := matchWithWorkers(t, titles, workers, configure)
+			for fileIndex := range files {
+				if !bytes.Equal(files[fileIndex], expected[fileIndex]) {
+					t.Errorf("%s configuration with %d workers wrote different %s than with 1", name, workers, fileKinds[fileIndex])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package matcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// listing outcomes returned by Listing.Outcome
+const (
+	OutcomeMatched   = "matched"
+	OutcomeAmbiguous = "ambiguous"
+	OutcomeUnmatched = "unmatched"
+)
+
+// Outcome returns whether the listing was matched, left unmatched because several products matched it equally well,
+// or left unmatched for any other reason
+func (l *Listing) Outcome() string {
+	if l.match != nil {
+		return OutcomeMatched
+	}
+	if l.decision == DecisionAmbiguous {
+		return OutcomeAmbiguous
+	}
+	return OutcomeUnmatched
+}
+
+// CompetingProducts returns the products that made the listing ambiguous
+func (l *Listing) CompetingProducts() []*Product {
+	return l.competingProducts
+}
+
+// ambiguousListing is the record written for each ambiguous listing
+type ambiguousListing struct {
+	Listing           *Listing `json:"listing"`
+	CompetingProducts []string `json:"competing_products"`
+}
+
+// getAmbiguousRecord returns the record written for the ambiguous listing
+func (l *Listing) getAmbiguousRecord() *ambiguousListing {
+	record := &ambiguousListing{Listing: l, CompetingProducts: []string{}}
+	for _, product := range l.competingProducts {
+		record.CompetingProducts = append(record.CompetingProducts, product.ProductName)
+	}
+	return record
+}
+
+// OutcomeCounts holds the number of listings with each outcome
+type OutcomeCounts struct {
+	Matched   int
+	Ambiguous int
+	Unmatched int
+}
+
+// add counts the listing's outcome
+func (oc *OutcomeCounts) add(listing *Listing) {
+	switch listing.Outcome() {
+	case OutcomeMatched:
+		oc.Matched++
+	case OutcomeAmbiguous:
+		oc.Ambiguous++
+	default:
+		oc.Unmatched++
+	}
+}
+
+// String returns a one line summary of the counts
+func (oc OutcomeCounts) String() string {
+	return fmt.Sprintf("%d matched, %d ambiguous, %d unmatched", oc.Matched, oc.Ambiguous, oc.Unmatched)
+}
+
+// GetOutcomeCounts returns the number of listings with each outcome
+func (l *Listings) GetOutcomeCounts() (outcomeCounts OutcomeCounts) {
+	for _, listing := range l.listings {
+		outcomeCounts.add(listing)
+	}
+	return
+}
+
+// ExportAmbiguousListings export the ambiguous listings with the names of their competing products, one JSON object
+// per line, to the given filename. Set ExcludeAmbiguous to keep them out of the unmatched listings.
+func (l *Listings) ExportAmbiguousListings(filename string) (err error) {
+	ambiguousListingsFile, err := os.Create(filename)
+	if err != nil {
+		fmt.Println("Error creating file for ambiguous listings:", err)
+		return err
+	}
+	defer ambiguousListingsFile.Close()
+	jsonEncoder := json.NewEncoder(ambiguousListingsFile)
+	ambiguousCount := 0
+	for _, listing := range l.listings {
+		if listing.Outcome() != OutcomeAmbiguous {
+			continue
+		}
+		ambiguousCount++
+		err = jsonEncoder.Encode(listing.getAmbiguousRecord())
+		if err != nil {
+			fmt.Println("Error exporting ambiguous listings to file", filename, ":", err)
+			return err
+		}
+	}
+	fmt.Println("Done writing", ambiguousCount, "ambiguous listings to", filename)
+	return nil
+}
@@ -0,0 +1,108 @@
+package matcher
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newAmbiguousTestCatalog returns two products only told apart by their family, so a title without it is ambiguous
+func newAmbiguousTestCatalog() []*Product {
+	return []*Product{
+		{ProductName: "Canon_PowerShot_SX210", Manufacturer: "Canon", Family: "PowerShot", Model: "SX210"},
+		{ProductName: "Canon_IXUS_SX210", Manufacturer: "Canon", Family: "IXUS", Model: "SX210"},
+	}
+}
+
+// newOutcomeTestListings returns a matched, an ambiguous and an unmatched listing
+func newOutcomeTestListings() []*Listing {
+	return []*Listing{
+		{Title: "Canon PowerShot SX210", Currency: "USD", Price: "199.99"},
+		{Title: "Canon SX210 digital camera", Currency: "USD", Price: "199.99"},
+		{Title: "Olympus Stylus", Currency: "USD", Price: "199.99"},
+	}
+}
+
+// readAmbiguousListings returns the titles and competing products of the ambiguous listings file
+func readAmbiguousListings(t *testing.T, filename string) (titles []string, competingProducts [][]string) {
+	for _, line := range readLines(t, filename) {
+		record := &ambiguousListing{}
+		if err := json.Unmarshal([]byte(line), record); err != nil {
+			t.Fatal(err)
+		}
+		titles = append(titles, record.Listing.Title)
+		competingProducts = append(competingProducts, record.CompetingProducts)
+	}
+	return
+}
+
+func TestListingOutcomes(t *testing.T) {
+	m := newTestMatcher(newAmbiguousTestCatalog(), nil)
+	listings := &Listings{ExcludeAmbiguous: true}
+	for _, listing := range newOutcomeTestListings() {
+		listings.Add(listing)
+	}
+	m.MatchListings(listings)
+	expectedOutcomes := []string{OutcomeMatched, OutcomeAmbiguous, OutcomeUnmatched}
+	for listingIndex, listing := range listings.listings {
+		if outcome := listing.Outcome(); outcome != expectedOutcomes[listingIndex] {
+			t.Errorf("%q outcome %q, expected %q", listing.Title, outcome, expectedOutcomes[listingIndex])
+		}
+	}
+	if competingProducts := listings.listings[1].CompetingProducts(); len(competingProducts) != 2 {
+		t.Errorf("ambiguous listing has %d competing products, expected 2", len(competingProducts))
+	}
+	if outcomeCounts := listings.GetOutcomeCounts(); outcomeCounts != (OutcomeCounts{Matched: 1, Ambiguous: 1, Unmatched: 1}) {
+		t.Errorf("outcome counts %v", outcomeCounts)
+	}
+	// the ambiguous listing goes to it's own file instead of the unmatched listings
+	directory := t.TempDir()
+	if err := listings.ExportAmbiguousListings(filepath.Join(directory, "ambiguous.txt")); err != nil {
+		t.Fatal(err)
+	}
+	titles, competingProducts := readAmbiguousListings(t, filepath.Join(directory, "ambiguous.txt"))
+	if !reflect.DeepEqual(titles, []string{"Canon SX210 digital camera"}) ||
+		!reflect.DeepEqual(competingProducts, [][]string{{"Canon_PowerShot_SX210", "Canon_IXUS_SX210"}}) {
+		t.Errorf("ambiguous listings %q with competing products %q", titles, competingProducts)
+	}
+	if err := listings.ExportUnmatchedListings(filepath.Join(directory, "unmatched.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if lines := readLines(t, filepath.Join(directory, "unmatched.txt")); len(lines) != 1 {
+		t.Errorf("%d unmatched listings written, expected only the unmatched one", len(lines))
+	}
+	listings.ExcludeAmbiguous = false
+	if err := listings.ExportUnmatchedListings(filepath.Join(directory, "unmatched.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if lines := readLines(t, filepath.Join(directory, "unmatched.txt")); len(lines) != 2 {
+		t.Errorf("%d unmatched listings written, expected the ambiguous and unmatched ones", len(lines))
+	}
+}
+
+func TestListingStreamOutcomes(t *testing.T) {
+	directory := t.TempDir()
+	ls, err := NewListingStream(newTestMatcher(newAmbiguousTestCatalog(), nil), filepath.Join(directory, "unmatched.txt"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ls.SetAmbiguousFile(filepath.Join(directory, "ambiguous.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err = streamListings(ls, newOutcomeTestListings()); err != nil {
+		t.Fatal(err)
+	}
+	if err = ls.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if outcomeCounts := ls.GetOutcomeCounts(); outcomeCounts != (OutcomeCounts{Matched: 1, Ambiguous: 1, Unmatched: 1}) {
+		t.Errorf("outcome counts %v", outcomeCounts)
+	}
+	if titles, _ := readAmbiguousListings(t, filepath.Join(directory, "ambiguous.txt")); !reflect.DeepEqual(titles, []string{"Canon SX210 digital camera"}) {
+		t.Errorf("ambiguous listings %q", titles)
+	}
+	if lines := readLines(t, filepath.Join(directory, "unmatched.txt")); len(lines) != 1 {
+		t.Errorf("%d unmatched listings streamed, expected only the unmatched one", len(lines))
+	}
+}
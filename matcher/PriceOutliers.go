@@ -0,0 +1,113 @@
+package matcher
+
+import (
+	"math"
+	"sort"
+)
+
+// PriceRejection describes why a listing was dropped by the price check, prices are in USD
+type PriceRejection struct {
+	Reason   string  `json:"reason"`
+	Price    float64 `json:"price"`
+	BandLow  float64 `json:"band_low"`
+	BandHigh float64 `json:"band_high"`
+	// Median, MAD and Score are only set by the median absolute deviation strategy
+	Median float64 `json:"median,omitempty"`
+	MAD    float64 `json:"mad,omitempty"`
+	Score  float64 `json:"score,omitempty"`
+}
+
+// minimumLogPriceScale keeps identically priced listings from making every other price an outlier
+const minimumLogPriceScale = 0.05
+
+// madToStandardDeviation scales a median absolute deviation to the standard deviation of normally distributed values
+const madToStandardDeviation = 1.4826
+
+// weightedValue is a value with the weight it carries when computing a weighted median
+type weightedValue struct {
+	value  float64
+	weight float64
+}
+
+// getWeightedMedian returns the value at which half of the total weight is reached
+func getWeightedMedian(weightedValues []weightedValue) float64 {
+	sortedValues := append([]weightedValue{}, weightedValues...)
+	sort.Slice(sortedValues, func(a, b int) bool { return sortedValues[a].value < sortedValues[b].value })
+	totalWeight := 0.0
+	for _, weightedValue := range sortedValues {
+		totalWeight += weightedValue.weight
+	}
+	cumulativeWeight := 0.0
+	for _, weightedValue := range sortedValues {
+		cumulativeWeight += weightedValue.weight
+		if cumulativeWeight >= totalWeight/2 {
+			return weightedValue.value
+		}
+	}
+	return 0
+}
+
+// getMatchWeight returns how much a matched listing's price counts, it's match confidence with the idf scoring model
+// or the weight of it's token order difference like in the best price range strategy
+func (r *Result) getMatchWeight(config *Config, listingIndex int) float64 {
+	if config.ScoringModel == ScoringModelIDF {
+		return r.confidences[listingIndex]
+	}
+	return float64(getWeightForTokenOrderDifference(r.tokenOrderDifferences[listingIndex]))
+}
+
+// dropPriceOutliers drops, for each product, the individual listings whose log price is more than the configured
+// number of median absolute deviations away from the median log price. Both are weighted by the match confidence.
+// Like with the best price range strategy, listings without a usable price are dropped.
+func (p *Products) dropPriceOutliers(config *Config, rates *CurrencyRates) (droppedListings []*Listing) {
+	for _, product := range p.products {
+		// gather the log prices weighted by the confidence in each match
+		logPrices := []weightedValue{}
+		for listingIndex, listing := range product.result.Listings {
+			price := listing.GetPrice(rates, -1)
+			if price <= 0 {
+				continue
+			}
+			logPrices = append(logPrices, weightedValue{value: math.Log(price), weight: product.result.getMatchWeight(config, listingIndex)})
+		}
+		// with too few prices to tell which ones are out of line, only the listings without a price are dropped
+		median, mad, scale := 0.0, 0.0, math.Inf(1)
+		if len(logPrices) >= 3 {
+			median = getWeightedMedian(logPrices)
+			deviations := make([]weightedValue, len(logPrices))
+			for priceIndex, logPrice := range logPrices {
+				deviations[priceIndex] = weightedValue{value: math.Abs(logPrice.value - median), weight: logPrice.weight}
+			}
+			mad = getWeightedMedian(deviations)
+			scale = math.Max(madToStandardDeviation*mad, minimumLogPriceScale)
+			product.result.priceBandLow = math.Exp(median - config.PriceOutlierThreshold*scale)
+			product.result.priceBandHigh = math.Exp(median + config.PriceOutlierThreshold*scale)
+		}
+		listingIndex := 0
+		for listingIndex < len(product.result.Listings) {
+			listing := product.result.Listings[listingIndex]
+			price := listing.GetPrice(rates, -1)
+			priceRejection := &PriceRejection{Reason: "no usable price", Price: price}
+			if price > 0 {
+				score := math.Abs(math.Log(price)-median) / scale
+				if score <= config.PriceOutlierThreshold {
+					listingIndex++
+					continue
+				}
+				priceRejection = &PriceRejection{
+					Reason:   "price outlier",
+					Price:    price,
+					BandLow:  product.result.priceBandLow,
+					BandHigh: product.result.priceBandHigh,
+					Median:   math.Exp(median),
+					MAD:      mad,
+					Score:    score,
+				}
+			}
+			listing.rejectPrice(priceRejection)
+			droppedListings = append(droppedListings, listing)
+			product.result.removeListing(listingIndex)
+		}
+	}
+	return
+}
@@ -0,0 +1,61 @@
+package matcher
+
+import "testing"
+
+func TestDropIrregularlyPricedResults(t *testing.T) {
+	prices := []string{"100", "105", "", "110", "98", "1000", "102"}
+	for _, strategy := range []string{PriceOutlierStrategyBestRange, PriceOutlierStrategyMAD} {
+		m := newTestMatcher(newTestCatalog(), func(config *Config) { config.PriceOutlierStrategy = strategy })
+		listings := &Listings{}
+		for _, price := range prices {
+			listings.Add(&Listing{Title: "Canon PowerShot SX210 IS", Currency: "USD", Price: price})
+		}
+		m.MatchListings(listings)
+		// both strategies drop the listing without a price and the one priced far from the others
+		for listingIndex, listing := range listings.listings {
+			dropped := listing.Decision() == DecisionPriceRejected
+			if expectedDropped := prices[listingIndex] == "" || prices[listingIndex] == "1000"; dropped != expectedDropped {
+				t.Errorf("%s strategy dropped %v the listing priced %q", strategy, dropped, prices[listingIndex])
+			}
+		}
+		if result := m.Products().products[1].Result(); len(result.Listings) != 5 || len(result.tokenOrderDifferences) != 5 || len(result.confidences) != 5 {
+			t.Errorf("%s strategy kept %d listings, expected 5", strategy, len(result.Listings))
+		}
+	}
+}
+
+func TestDropPriceOutliersWithFewPrices(t *testing.T) {
+	m := newTestMatcher(newTestCatalog(), func(config *Config) { config.PriceOutlierStrategy = PriceOutlierStrategyMAD })
+	listings := &Listings{}
+	for _, price := range []string{"100", "1000", "call"} {
+		listings.Add(&Listing{Title: "Sony DSC-W310", Currency: "USD", Price: price})
+	}
+	m.MatchListings(listings)
+	// two prices can't tell which one is out of line, but the listing without a price is still dropped
+	if result := m.Products().products[0].Result(); len(result.Listings) != 2 || listings.listings[2].PriceRejection() == nil {
+		t.Errorf("kept %d listings, expected the 2 with a price", len(result.Listings))
+	}
+}
+
+func TestGetMatchWeight(t *testing.T) {
+	result := &Result{tokenOrderDifferences: []int{0, 6}, confidences: []float64{0.9, 0.3}}
+	config := DefaultConfig()
+	if weight := result.getMatchWeight(&config, 0); weight != 49 {
+		t.Errorf("token order weight %v, expected 49", weight)
+	}
+	config.ScoringModel = ScoringModelIDF
+	if weight := result.getMatchWeight(&config, 1); weight != 0.3 {
+		t.Errorf("idf weight %v, expected the 0.3 confidence", weight)
+	}
+}
+
+func TestGetWeightedMedian(t *testing.T) {
+	values := []weightedValue{{value: 1, weight: 1}, {value: 5, weight: 3}, {value: 2, weight: 1}}
+	if median := getWeightedMedian(values); median != 5 {
+		t.Errorf("weighted median %v, expected 5", median)
+	}
+	values[1].weight = 0.5
+	if median := getWeightedMedian(values); median != 2 {
+		t.Errorf("weighted median %v, expected 2", median)
+	}
+}
@@ -0,0 +1,200 @@
+package matcher
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// PriceStatus tells how a listing's price was parsed
+type PriceStatus int
+
+// price statuses set by parsePrice
+const (
+	PriceUnparsed PriceStatus = iota
+	PriceValid
+	PriceRange // the price is the middle of the range
+	PriceMissing
+	PriceInvalid
+)
+
+// String returns the name of the price status
+func (ps PriceStatus) String() string {
+	switch ps {
+	case PriceValid:
+		return "valid"
+	case PriceRange:
+		return "range"
+	case PriceMissing:
+		return "missing"
+	case PriceInvalid:
+		return "invalid"
+	}
+	return "unparsed"
+}
+
+// currencySymbols maps the currency symbols found in prices to their ISO 4217 code, longer symbols first
+var currencySymbols = []struct {
+	symbol   string
+	currency string
+}{
+	{"CAD$", "CAD"}, {"CDN$", "CAD"}, {"CA$", "CAD"}, {"C$", "CAD"}, {"US$", "USD"}, {"AU$", "AUD"}, {"A$", "AUD"},
+	{"$", "USD"}, {"€", "EUR"}, {"£", "GBP"}, {"¥", "JPY"}, {"₹", "INR"}, {"kr", "SEK"}, {"Fr.", "CHF"},
+}
+
+// currencyCodes are the ISO 4217 codes recognized in prices, mapped to themselves, along with common non ISO codes
+// mapped to the ISO code they stand for
+var currencyCodes = map[string]string{"CDN": "CAD"}
+
+func init() {
+	for _, code := range strings.Fields(`AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BRL BSD BTN
+		BWP BYN BZD CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD
+		GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR
+		LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR NZD OMR PAB PEN PGK PHP
+		PKR PLN PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD SHP SLE SOS SRD SSP STN SYP SZL THB TJS TMT TND TOP TRY
+		TTD TWD TZS UAH UGX USD UYU UZS VES VND VUV WST XAF XCD XOF XPF YER ZAR ZMW ZWL`) {
+		currencyCodes[code] = code
+	}
+}
+
+// rangeSeparators separate the bounds of a price range
+var rangeSeparators = []string{" to ", " - ", "–", "-", "~"}
+
+// extractCurrency removes a currency code or symbol from the price and returns the currency it names. Only known
+// codes written in upper case are recognized, so that words such as "Now" or "env." aren't taken for currencies.
+func extractCurrency(value string) (remainingValue string, currency string) {
+	// look for a currency code first, it's more specific than a symbol, e.g. "EUR 1.299,00" or "CAD $349.99"
+	fields := strings.FieldsFunc(value, func(r rune) bool { return !unicode.IsLetter(r) })
+	for _, field := range fields {
+		if code, found := currencyCodes[field]; found {
+			return strings.Replace(value, field, " ", 1), code
+		}
+	}
+	for _, currencySymbol := range currencySymbols {
+		if strings.Contains(value, currencySymbol.symbol) {
+			return strings.Replace(value, currencySymbol.symbol, " ", -1), currencySymbol.currency
+		}
+	}
+	return value, ""
+}
+
+// isDigitGroup returns true if the runes start with exactly 3 digits, the size of a thousands group
+func isDigitGroup(runes []rune) bool {
+	digitCount := 0
+	for digitCount < len(runes) && unicode.IsDigit(runes[digitCount]) {
+		digitCount++
+	}
+	return digitCount == 3
+}
+
+// getNumbers returns the runs of digits and separators found in the value. Spaces and apostrophes only join a
+// group of 3 digits to the number before it, and separators at the ends of a number are dropped, e.g. "$349.99."
+func getNumbers(value string) (numbers []string) {
+	runes := []rune(value)
+	number := []rune{}
+	endNumber := func() {
+		number = []rune(strings.TrimRight(string(number), ".,"))
+		if len(number) > 0 {
+			numbers = append(numbers, string(number))
+		}
+		number = []rune{}
+	}
+	for i, r := range runes {
+		switch {
+		case r >= '0' && r <= '9':
+			number = append(number, r)
+		case r == '.' || r == ',':
+			if len(number) > 0 {
+				number = append(number, r)
+			} else if i+1 < len(runes) && unicode.IsDigit(runes[i+1]) {
+				number = append(number, '0', r)
+			}
+		case (r == ' ' || r == '\'' || r == '\u00a0' || r == '\u202f') && len(number) > 0 && isDigitGroup(runes[i+1:]):
+			continue
+		default:
+			endNumber()
+		}
+	}
+	endNumber()
+	return
+}
+
+// parseAmount parses a single amount with optional thousands separators and a decimal point or comma,
+// values with more than one number, e.g. "12 x 99.99", are rejected
+func parseAmount(value string) (amount float64, err error) {
+	numbers := getNumbers(value)
+	if len(numbers) != 1 {
+		return 0, fmt.Errorf("expected one amount in %q, found %d", value, len(numbers))
+	}
+	number := numbers[0]
+	lastDot := strings.LastIndex(number, ".")
+	lastComma := strings.LastIndex(number, ",")
+	decimalSeparator := ""
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		// with both separators the last one is the decimal separator
+		if lastDot > lastComma {
+			decimalSeparator = "."
+		} else {
+			decimalSeparator = ","
+		}
+	case lastDot >= 0 || lastComma >= 0:
+		separator := "."
+		separatorIndex := lastDot
+		if lastComma >= 0 {
+			separator = ","
+			separatorIndex = lastComma
+		}
+		// a separator used once is a decimal separator unless it's followed by exactly 3 digits, e.g. "1.299"
+		if strings.Count(number, separator) == 1 && (len(number)-separatorIndex-1 != 3 || strings.HasPrefix(number, "0")) {
+			decimalSeparator = separator
+		}
+	}
+	if decimalSeparator != "" {
+		decimalIndex := strings.LastIndex(number, decimalSeparator)
+		integerPart := strings.NewReplacer(".", "", ",", "").Replace(number[:decimalIndex])
+		number = integerPart + "." + number[decimalIndex+1:]
+	} else {
+		number = strings.NewReplacer(".", "", ",", "").Replace(number)
+	}
+	return strconv.ParseFloat(number, 64)
+}
+
+// isNegative returns true if the amount starts with a minus sign, which the amount parsing would ignore
+func isNegative(value string) bool {
+	value = strings.TrimSpace(value)
+	return strings.HasPrefix(value, "-") || strings.HasPrefix(value, "−")
+}
+
+// parsePrice parses prices such as "1.299,00", "1 299 €", "$349.99", "349,99" or "100-150", returning the currency
+// named by a symbol or code in the price if there is one. Negative prices are invalid.
+func parsePrice(value string) (price float64, currency string, status PriceStatus) {
+	value, currency = extractCurrency(strings.TrimSpace(value))
+	if strings.IndexFunc(value, unicode.IsDigit) < 0 {
+		if strings.TrimSpace(value) == "" {
+			return 0, currency, PriceMissing
+		}
+		return 0, currency, PriceInvalid
+	}
+	if isNegative(value) {
+		return 0, currency, PriceInvalid
+	}
+	for _, rangeSeparator := range rangeSeparators {
+		bounds := strings.Split(value, rangeSeparator)
+		if len(bounds) != 2 || strings.IndexFunc(bounds[0], unicode.IsDigit) < 0 || strings.IndexFunc(bounds[1], unicode.IsDigit) < 0 {
+			continue
+		}
+		lowPrice, lowErr := parseAmount(bounds[0])
+		highPrice, highErr := parseAmount(bounds[1])
+		if lowErr != nil || highErr != nil || isNegative(bounds[1]) {
+			return 0, currency, PriceInvalid
+		}
+		return (lowPrice + highPrice) / 2, currency, PriceRange
+	}
+	price, err := parseAmount(value)
+	if err != nil {
+		return 0, currency, PriceInvalid
+	}
+	return price, currency, PriceValid
+}
@@ -0,0 +1,48 @@
+package matcher
+
+import "testing"
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		value    string
+		price    float64
+		currency string
+		status   PriceStatus
+	}{
+		{"99.99", 99.99, "", PriceValid},
+		{"1.299,00", 1299, "", PriceValid},
+		{"1 299 €", 1299, "EUR", PriceValid},
+		{"$349.99", 349.99, "USD", PriceValid},
+		{"349,99", 349.99, "", PriceValid},
+		{"1,299", 1299, "", PriceValid},
+		{"0,299", 0.299, "", PriceValid},
+		{"1'299.50", 1299.5, "", PriceValid},
+		{"EUR 1.299,00", 1299, "EUR", PriceValid},
+		{"349.99 USD", 349.99, "USD", PriceValid},
+		{"CAD $349.99", 349.99, "CAD", PriceValid},
+		{"100-150", 125, "", PriceRange},
+		{"£100 to £150", 125, "GBP", PriceRange},
+		{"", 0, "", PriceMissing},
+		{"call", 0, "", PriceInvalid},
+		// words that look like currency codes
+		{"Now $99.99", 99.99, "USD", PriceValid},
+		{"CDN$ 99.99", 99.99, "CAD", PriceValid},
+		{"99.99 env.", 99.99, "", PriceValid},
+		// trailing punctuation isn't a thousands separator
+		{"$349.99.", 349.99, "USD", PriceValid},
+		{"349.99 USD.", 349.99, "USD", PriceValid},
+		// negative prices aren't taken for a range or the amount without it's sign
+		{"-5", 0, "", PriceInvalid},
+		{"-$5.00", 0, "USD", PriceInvalid},
+		{"USD -5", 0, "USD", PriceInvalid},
+		{"10 - -5", 0, "", PriceInvalid},
+		// several numbers can't be told apart
+		{"12 x 99.99", 0, "", PriceInvalid},
+	}
+	for _, test := range tests {
+		price, currency, status := parsePrice(test.value)
+		if status != test.status || currency != test.currency || status != PriceInvalid && price != test.price {
+			t.Errorf("parsePrice(%q) = %v, %q, %v, expected %v, %q, %v", test.value, price, currency, status, test.price, test.currency, test.status)
+		}
+	}
+}
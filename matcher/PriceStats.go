@@ -0,0 +1,52 @@
+package matcher
+
+import (
+	"sort"
+	"strings"
+)
+
+// PriceStats holds the price statistics of a product's matched listings, prices are normalized to USD
+type PriceStats struct {
+	MinPrice            float64        `json:"min_price"`
+	MedianPrice         float64        `json:"median_price"`
+	MaxPrice            float64        `json:"max_price"`
+	ListingsPerCurrency map[string]int `json:"listings_per_currency"`
+	// the accepted price band computed by the price check, omitted if the product had too few prices for one
+	BandLow  float64 `json:"band_low,omitempty"`
+	BandHigh float64 `json:"band_high,omitempty"`
+}
+
+// getPriceStats computes the price statistics of the product's matched listings
+func (p *Product) getPriceStats(rates *CurrencyRates) (priceStats *PriceStats) {
+	priceStats = &PriceStats{
+		ListingsPerCurrency: map[string]int{},
+		BandLow:             p.result.priceBandLow,
+		BandHigh:            p.result.priceBandHigh,
+	}
+	prices := []float64{}
+	for _, listing := range p.result.Listings {
+		priceStats.ListingsPerCurrency[strings.ToUpper(listing.GetCurrency())]++
+		if price := listing.GetPrice(rates, -1); price >= 0 {
+			prices = append(prices, price)
+		}
+	}
+	if len(prices) == 0 {
+		return
+	}
+	sort.Float64s(prices)
+	priceStats.MinPrice = prices[0]
+	priceStats.MaxPrice = prices[len(prices)-1]
+	if len(prices)%2 == 1 {
+		priceStats.MedianPrice = prices[len(prices)/2]
+	} else {
+		priceStats.MedianPrice = (prices[len(prices)/2-1] + prices[len(prices)/2]) / 2
+	}
+	return
+}
+
+// setPriceStats adds the price statistics to the results of every product
+func (p *Products) setPriceStats(rates *CurrencyRates) {
+	for _, product := range p.products {
+		product.result.PriceStats = product.getPriceStats(rates)
+	}
+}
@@ -0,0 +1,64 @@
+package matcher
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPriceStats(t *testing.T) {
+	m := newTestMatcher(newTestCatalog(), func(config *Config) { config.PriceStatistics = true })
+	rates := NewCurrencyRates()
+	rates.AddRate("CAD", time.Time{}, 2)
+	m.SetCurrencyRates(rates)
+	listings := &Listings{}
+	for _, price := range []struct{ currency, price string }{{"USD", "100"}, {"CAD", "240"}, {"usd", "110"}, {"CAD", "180"}} {
+		listings.Add(&Listing{Title: "Samsung TL240", Currency: price.currency, Price: price.price})
+	}
+	m.MatchListings(listings)
+	priceStats := m.Products().products[3].Result().PriceStats
+	if priceStats == nil {
+		t.Fatal("no price statistics")
+	}
+	// the prices are compared in US dollars: 90, 100, 110 and 120
+	if priceStats.MinPrice != 90 || priceStats.MedianPrice != 105 || priceStats.MaxPrice != 120 {
+		t.Errorf("prices %v, %v, %v, expected 90, 105, 120", priceStats.MinPrice, priceStats.MedianPrice, priceStats.MaxPrice)
+	}
+	if len(priceStats.ListingsPerCurrency) != 2 || priceStats.ListingsPerCurrency["USD"] != 2 || priceStats.ListingsPerCurrency["CAD"] != 2 {
+		t.Errorf("listings per currency %v", priceStats.ListingsPerCurrency)
+	}
+	if priceStats.BandLow != 90 || priceStats.BandHigh != 120 {
+		t.Errorf("price band %v to %v, expected 90 to 120", priceStats.BandLow, priceStats.BandHigh)
+	}
+	// products without listings still get empty statistics in the exported results
+	filename := filepath.Join(t.TempDir(), "results.txt")
+	if err := m.Products().ExportResults(filename); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		result := &Result{}
+		if err = json.Unmarshal([]byte(line), result); err != nil {
+			t.Fatal(err)
+		}
+		if result.PriceStats == nil {
+			t.Errorf("no price statistics exported for %s", result.ProductName)
+		}
+	}
+}
+
+func TestPriceStatsOffByDefault(t *testing.T) {
+	m := newTestMatcher(newTestCatalog(), nil)
+	listings := &Listings{}
+	listings.Add(&Listing{Title: "Samsung TL240", Currency: "USD", Price: "100"})
+	m.MatchListings(listings)
+	if m.Products().products[3].Result().PriceStats != nil {
+		t.Error("price statistics added without Config.PriceStatistics")
+	}
+}
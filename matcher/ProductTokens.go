@@ -0,0 +1,133 @@
+package matcher
+
+import (
+	"strings"
+
+	"github.com/Scalu/sortablechallenge/sortablechallengeutils"
+)
+
+// productToken holds the value of a token and a list of products it appears in
+type productToken struct {
+	value    string
+	products []*Product
+}
+
+// hasProduct returns true if the product is already in the token's list of products that it appears in
+func (pt *productToken) hasProduct(desiredProduct *Product) bool {
+	for _, product := range pt.products {
+		if product == desiredProduct {
+			return true
+		}
+	}
+	return false
+}
+
+// ProductTokens This structure and it's methods handle the product tokens
+// These tokens are used to matching the listings to products
+type ProductTokens struct {
+	tokens        []productToken
+	freeIndexes   []int                                           // indexes in tokens of removed tokens, reused by AddTokens
+	tokenIndexes  *sortablechallengeutils.OrderedMap[string, int] // token value to it's index in tokens
+	fuzzyIndex    tokenBKTree
+	modelKeys     map[string][]*Product // joined model numbers to the products with that model
+	manufacturers manufacturerIndex
+}
+
+// newProductTokens returns an empty set of product tokens
+func newProductTokens() *ProductTokens {
+	return &ProductTokens{
+		tokenIndexes: sortablechallengeutils.NewOrderedMap[string, int](strings.Compare),
+		modelKeys:    map[string][]*Product{},
+	}
+}
+
+// Search find the token index containing this string value, safe to call from concurrent goroutines
+func (pt *ProductTokens) Search(stringValue string) (index int) {
+	index, found := pt.tokenIndexes.Get(stringValue)
+	if !found {
+		return -1
+	}
+	return index
+}
+
+// GetMatchingToken returns a product token that matches a given string
+func (pt *ProductTokens) getMatchingToken(value string) (matchingToken *productToken) {
+	matchingIndex := pt.Search(value)
+	if matchingIndex > -1 {
+		return &pt.tokens[matchingIndex]
+	}
+	return nil
+}
+
+// AddTokens Adds tokens to the tokens array
+func (pt *ProductTokens) AddTokens(product *Product, signature []string) (tokenList []int) {
+	//build the signature
+	for _, tokenString := range signature {
+		tokenIndex, found := pt.tokenIndexes.Get(tokenString)
+		if !found {
+			// reuse the slot of a removed token first, like OrderedMap does
+			if len(pt.freeIndexes) > 0 {
+				tokenIndex = pt.freeIndexes[len(pt.freeIndexes)-1]
+				pt.freeIndexes = pt.freeIndexes[:len(pt.freeIndexes)-1]
+				pt.tokens[tokenIndex] = productToken{value: tokenString}
+			} else {
+				pt.tokens = append(pt.tokens, productToken{value: tokenString})
+				tokenIndex = len(pt.tokens) - 1
+			}
+			pt.tokenIndexes.Put(tokenString, tokenIndex)
+			pt.fuzzyIndex.add(tokenString, tokenIndex)
+		}
+		if !pt.tokens[tokenIndex].hasProduct(product) {
+			pt.tokens[tokenIndex].products = append(pt.tokens[tokenIndex].products, product)
+			tokenList = append(tokenList, tokenIndex)
+		}
+	}
+	return
+}
+
+// RemoveProduct removes the product from it's tokens. Tokens left without products are removed from the token tree
+// and the fuzzy index, and their slot is freed for the next token added. Not safe to call while listings are being matched.
+func (pt *ProductTokens) RemoveProduct(product *Product) {
+	for _, tokenIndex := range product.tokenList {
+		token := &pt.tokens[tokenIndex]
+		for productIndex, tokenProduct := range token.products {
+			if tokenProduct == product {
+				token.products = append(token.products[:productIndex], token.products[productIndex+1:]...)
+				break
+			}
+		}
+		if len(token.products) == 0 {
+			pt.tokenIndexes.Delete(token.value)
+			pt.fuzzyIndex.remove(token.value)
+			pt.freeIndexes = append(pt.freeIndexes, tokenIndex)
+			*token = productToken{}
+		}
+	}
+	product.tokenList = nil
+	modelKeyProducts := pt.modelKeys[product.modelKey]
+	for productIndex, modelKeyProduct := range modelKeyProducts {
+		if modelKeyProduct == product {
+			pt.modelKeys[product.modelKey] = append(modelKeyProducts[:productIndex], modelKeyProducts[productIndex+1:]...)
+			break
+		}
+	}
+}
+
+// addModelKey indexes the product by it's joined model number, for models with both letters and digits
+func (pt *ProductTokens) addModelKey(product *Product) {
+	product.modelKey = strings.Join(product.modelTokens, "")
+	if strings.IndexAny(product.modelKey, "0123456789") < 0 || strings.Trim(product.modelKey, "0123456789") == "" {
+		return
+	}
+	pt.modelKeys[product.modelKey] = append(pt.modelKeys[product.modelKey], product)
+}
+
+// GetVocabulary returns the token values in sorted order
+func (pt *ProductTokens) GetVocabulary() (vocabulary []string) {
+	vocabulary = make([]string, 0, pt.tokenIndexes.Len())
+	pt.tokenIndexes.Ascend(func(tokenValue string, tokenIndex int) bool {
+		vocabulary = append(vocabulary, tokenValue)
+		return true
+	})
+	return
+}
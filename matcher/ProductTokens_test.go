@@ -0,0 +1,73 @@
+package matcher
+
+import (
+	"reflect"
+	"testing"
+)
+
+// searchFuzzyValues returns the values of the tokens SearchFuzzy finds
+func searchFuzzyValues(pt *ProductTokens, value string, maxDistance int) (values []string) {
+	for _, candidate := range pt.SearchFuzzy(value, maxDistance) {
+		values = append(values, candidate.Value)
+	}
+	return
+}
+
+func TestRemoveProductThenAddTokens(t *testing.T) {
+	pt := newProductTokens()
+	sx210 := &Product{ProductName: "Canon_PowerShot_SX210"}
+	sx200 := &Product{ProductName: "Canon_PowerShot_SX200"}
+	sx210.tokenList = pt.AddTokens(sx210, []string{"canon", "powershot", "sx210"})
+	sx200.tokenList = pt.AddTokens(sx200, []string{"canon", "powershot", "sx200"})
+	pt.RemoveProduct(sx210)
+	// the orphaned token is gone from the token tree and the fuzzy index, and it's slot is free
+	if pt.Search("sx210") >= 0 || len(pt.freeIndexes) != 1 {
+		t.Fatalf("orphaned token still indexed, %d free indexes", len(pt.freeIndexes))
+	}
+	if values := searchFuzzyValues(pt, "sx210", 1); !reflect.DeepEqual(values, []string{"sx200"}) {
+		t.Errorf("fuzzy search found %v after the removal, expected [sx200]", values)
+	}
+	if tokenIndex := pt.Search("canon"); tokenIndex < 0 || !reflect.DeepEqual(pt.tokens[tokenIndex].products, []*Product{sx200}) {
+		t.Errorf("shared token doesn't hold only the remaining product")
+	}
+	// adding the token back reuses the free slot and the fuzzy index node
+	tokenCount, nodeCount := len(pt.tokens), len(pt.fuzzyIndex.nodes)
+	sx210.tokenList = pt.AddTokens(sx210, []string{"canon", "powershot", "sx210"})
+	if len(pt.tokens) != tokenCount || len(pt.freeIndexes) != 0 || len(pt.fuzzyIndex.nodes) != nodeCount {
+		t.Errorf("adding the token back grew the tokens from %d to %d and the fuzzy index from %d to %d nodes",
+			tokenCount, len(pt.tokens), nodeCount, len(pt.fuzzyIndex.nodes))
+	}
+	if values := searchFuzzyValues(pt, "sx210", 0); !reflect.DeepEqual(values, []string{"sx210"}) {
+		t.Errorf("fuzzy search found %v for the token added back, expected it once", values)
+	}
+	if tokenIndex := pt.Search("sx210"); tokenIndex < 0 || !reflect.DeepEqual(pt.tokens[tokenIndex].products, []*Product{sx210}) {
+		t.Errorf("token added back doesn't hold it's product")
+	}
+	// a new token takes the next free slot
+	pt.RemoveProduct(sx200)
+	freeIndexes := append([]int{}, pt.freeIndexes...)
+	pt.AddTokens(&Product{ProductName: "Canon_PowerShot_S95"}, []string{"s95"})
+	if tokenIndex := pt.Search("s95"); tokenIndex != freeIndexes[len(freeIndexes)-1] {
+		t.Errorf("new token at %d, expected the free index %d", tokenIndex, freeIndexes[len(freeIndexes)-1])
+	}
+	if !reflect.DeepEqual(pt.GetVocabulary(), []string{"canon", "powershot", "s95", "sx210"}) {
+		t.Errorf("vocabulary %v", pt.GetVocabulary())
+	}
+}
+
+func TestMatcherRemoveProduct(t *testing.T) {
+	m := newTestMatcher(newTestCatalog(), nil)
+	product := m.Products().products[1]
+	if !m.RemoveProduct(product) {
+		t.Fatal("product not removed")
+	}
+	if m.RemoveProduct(product) {
+		t.Error("product removed twice")
+	}
+	if matchResult := m.Match(&Listing{Title: "Canon PowerShot SX210 IS"}); matchResult.Product != nil {
+		t.Errorf("listing for the removed product matched %s", matchResult.Product.ProductName)
+	}
+	if matchResult := m.Match(&Listing{Title: "Canon PowerShot SX200 IS"}); matchResult.Product == nil {
+		t.Errorf("remaining product no longer matched, decision %q", matchResult.Decision)
+	}
+}
@@ -0,0 +1,252 @@
+package matcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Result contains matching results to be exported
+type Result struct {
+	ProductName           string      `json:"product_name"`
+	Listings              []*Listing  `json:"listings"`
+	PriceStats            *PriceStats `json:"price_stats,omitempty"` // only set when Config.PriceStatistics is true
+	tokenOrderDifferences []int
+	confidences           []float64 // the match confidence of each listing, 0 unless the idf scoring model is used
+	priceBandLow          float64   // accepted price band computed by dropIrregularlyPricedResults
+	priceBandHigh         float64
+}
+
+// Product defines the fields found in the products.txt json file
+type Product struct {
+	ProductName            string    `json:"product_name"`
+	Manufacturer           string    `json:"manufacturer"`
+	Model                  string    `json:"model"`
+	Family                 string    `json:"family"`
+	AnnouncedDate          string    `json:"announced-date"`
+	LegacyAnnouncedDate    string    `json:"announced_date,omitempty"` // key used by older product files, see parseAnnouncedDate
+	announcedDate          time.Time // parsed AnnouncedDate, zero if it's unknown
+	manufacturerName       string    // canonical manufacturer name, see Manufacturers.go
+	manufacturerTokenCount int
+	familyTokenCount       int
+	modelTokens            []string
+	modelKey               string // the model tokens joined together, see generateJoinedTokens
+	tokenList              []int
+	result                 Result
+}
+
+// removeListing removes the listing at the given index from the results, along with it's match details
+func (r *Result) removeListing(listingIndex int) {
+	r.Listings = append(r.Listings[:listingIndex], r.Listings[listingIndex+1:]...)
+	r.tokenOrderDifferences = append(r.tokenOrderDifferences[:listingIndex], r.tokenOrderDifferences[listingIndex+1:]...)
+	r.confidences = append(r.confidences[:listingIndex], r.confidences[listingIndex+1:]...)
+}
+
+// Result returns the matching results for the product
+func (p *Product) Result() *Result {
+	return &p.result
+}
+
+// Products implements common interface for loading json data
+type Products struct {
+	FileName            string
+	products            []*Product
+	matchedProductCount int
+}
+
+// GetFileName used by JSONArchive.go
+func (p *Products) GetFileName() string {
+	if p.FileName != "" {
+		return p.FileName
+	}
+	return "products.txt"
+}
+
+// Decode used by JSONArchive.go
+func (p *Products) Decode(decoder *json.Decoder) (err error) {
+	product := &Product{}
+	err = decoder.Decode(&product)
+	if err == nil {
+		p.Add(product)
+	}
+	return
+}
+
+// Add appends a product to the catalog and initializes it's result
+func (p *Products) Add(product *Product) {
+	product.result.ProductName = product.ProductName
+	product.result.Listings = []*Listing{}
+	product.parseAnnouncedDate()
+	p.products = append(p.products, product)
+}
+
+// remove takes a product out of the catalog, returning false if it wasn't in it
+func (p *Products) remove(product *Product) bool {
+	for productIndex, catalogProduct := range p.products {
+		if catalogProduct == product {
+			p.products = append(p.products[:productIndex], p.products[productIndex+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// GetTokens returns a ProductTokens object initialized by the products, with their fields tokenized by the given tokenizers
+func (p *Products) GetTokens(tokenizers *FieldTokenizers) (productTokens *ProductTokens) {
+	productTokens = newProductTokens()
+	for _, product := range p.products {
+		tokenArray := []string{}
+		tokenArray = append(tokenArray, tokenizers.Manufacturer.Tokenize(product.Manufacturer)...)
+		product.manufacturerTokenCount = len(tokenArray)
+		product.manufacturerName = productTokens.manufacturers.add(tokenArray)
+		tokenArray = append(tokenArray, tokenizers.Family.Tokenize(product.Family)...)
+		product.familyTokenCount = len(tokenArray) - product.manufacturerTokenCount
+		product.modelTokens = tokenizers.Model.Tokenize(product.Model)
+		tokenArray = append(tokenArray, product.modelTokens...)
+		product.tokenList = productTokens.AddTokens(product, tokenArray)
+		productTokens.addModelKey(product)
+	}
+	fmt.Println("Product tokens generated. ", len(p.products), " products, ", len(productTokens.tokens), " tokens")
+	return
+}
+
+// GetProductCount returns the number of products in the array
+func (p *Products) GetProductCount() int {
+	return len(p.products)
+}
+
+// getWeightForTokenOrderDifference gets a weight value based on the given tokenOrderDifference
+func getWeightForTokenOrderDifference(tokenOrderDifference int) (weight int) {
+	weight = 1
+	if tokenOrderDifference < 6 {
+		weight = (7 - tokenOrderDifference) * (7 - tokenOrderDifference)
+	}
+	return
+}
+
+// dropIrregularlyPricedResults checked that prices for products are consistent throughout the matches and drop inconsistent results,
+// using the strategy selected by the configuration
+func (p *Products) dropIrregularlyPricedResults(config *Config, rates *CurrencyRates) (droppedListings []*Listing) {
+	if config.PriceOutlierStrategy == PriceOutlierStrategyMAD {
+		return p.dropPriceOutliers(config, rates)
+	}
+	return p.dropPricesOutsideBestRange(config, rates)
+}
+
+// dropPricesOutsideBestRange finds the price range with the most weight for each product and drops the listings too far outside of it.
+// All the product's listings are dropped when that range doesn't hold half of the total weight.
+func (p *Products) dropPricesOutsideBestRange(config *Config, rates *CurrencyRates) (droppedListings []*Listing) {
+	// calculate the best range
+	var bestRangeStartPrice, bestRangeMaxValue, bestRangeSpread float64
+	var currentRangeStartPrice, currentRangeMaxValue, currentRangeSpread float64
+	var secondListingPrice float64
+	var bestRangeWeightValue, currentRangeWeightValue, listingIndex, secondIndex, totalWeight int
+	var listing, secondListing *Listing
+	var product *Product
+	maxRangeSpread := config.MaxPriceRangeSpread
+	for _, product = range p.products {
+		if len(product.result.Listings) == 0 {
+			continue
+		}
+		bestRangeStartPrice = 0.0
+		bestRangeMaxValue = 0.0
+		bestRangeSpread = 0.0
+		bestRangeWeightValue = 0
+		totalWeight = 0
+		for listingIndex, listing = range product.result.Listings {
+			currentRangeStartPrice = listing.GetPrice(rates, -1.0)
+			if currentRangeStartPrice < 0 {
+				continue
+			}
+			currentRangeMaxValue = currentRangeStartPrice
+			currentRangeWeightValue = 0
+			totalWeight += getWeightForTokenOrderDifference(product.result.tokenOrderDifferences[listingIndex])
+			for secondIndex, secondListing = range product.result.Listings {
+				secondListingPrice = secondListing.GetPrice(rates, -1.0)
+				if secondListingPrice < 0 {
+					continue
+				}
+				if secondListingPrice >= currentRangeStartPrice && secondListingPrice <= currentRangeStartPrice*maxRangeSpread {
+					currentRangeWeightValue += getWeightForTokenOrderDifference(product.result.tokenOrderDifferences[secondIndex])
+					if secondListingPrice > currentRangeMaxValue {
+						currentRangeMaxValue = secondListingPrice
+					}
+				}
+			}
+			currentRangeSpread = currentRangeMaxValue / currentRangeStartPrice
+			if currentRangeWeightValue > bestRangeWeightValue ||
+				currentRangeWeightValue == bestRangeWeightValue && currentRangeSpread < bestRangeSpread {
+				bestRangeStartPrice = currentRangeStartPrice
+				bestRangeWeightValue = currentRangeWeightValue
+				bestRangeMaxValue = currentRangeMaxValue
+				bestRangeSpread = currentRangeSpread
+			}
+		}
+		// remove all listings if weight value in spread is not high enough
+		var listing *Listing
+		if bestRangeWeightValue < totalWeight/2 {
+			fmt.Println("Warning spread out pricing for product", product.ProductName, "could indicate bad matching. Discarding matches")
+			for _, listing = range product.result.Listings {
+				listing.rejectPrice(&PriceRejection{
+					Reason:   fmt.Sprintf("prices for %s too spread out, all of it's matches discarded", product.ProductName),
+					Price:    listing.GetPrice(rates, -1),
+					BandLow:  bestRangeStartPrice,
+					BandHigh: bestRangeMaxValue,
+				})
+			}
+			droppedListings = append(droppedListings, product.result.Listings...)
+			product.result.Listings = []*Listing{}
+			product.result.tokenOrderDifferences = []int{}
+			product.result.confidences = []float64{}
+			continue
+		}
+		product.result.priceBandLow = bestRangeStartPrice
+		product.result.priceBandHigh = bestRangeMaxValue
+		// drop listings the deviate too far out from the spread
+		listingIndex := 0
+		var allowedVariance, currentListingPrice float64
+		var currentListingWeight int
+		for listingIndex < len(product.result.Listings) {
+			listing = product.result.Listings[listingIndex]
+			currentListingPrice = listing.GetPrice(rates, -1)
+			currentListingWeight = getWeightForTokenOrderDifference(product.result.tokenOrderDifferences[listingIndex])
+			allowedVariance = 1.0 + config.PriceVarianceFactor*float64(currentListingWeight)
+			if currentListingPrice < bestRangeStartPrice/allowedVariance || currentListingPrice > bestRangeMaxValue*allowedVariance {
+				listing.rejectPrice(&PriceRejection{
+					Reason:   "price outside of the accepted range",
+					Price:    currentListingPrice,
+					BandLow:  bestRangeStartPrice / allowedVariance,
+					BandHigh: bestRangeMaxValue * allowedVariance,
+				})
+				droppedListings = append(droppedListings, listing)
+				product.result.removeListing(listingIndex)
+			} else {
+				listingIndex++
+			}
+		}
+	}
+	return
+}
+
+// ExportResults export the results in JSON format to the given filename
+func (p *Products) ExportResults(filename string) (err error) {
+	resultsFile, err := os.Create(filename)
+	if err != nil {
+		fmt.Println("Error creating file for results:", err)
+		return err
+	}
+	defer resultsFile.Close()
+	jsonEncoder := json.NewEncoder(resultsFile)
+	p.matchedProductCount = 0
+	for _, product := range p.products {
+		err = jsonEncoder.Encode(product.result)
+		if err != nil {
+			fmt.Println("Error exporting results to file", filename, ":", err)
+			return err
+		}
+		p.matchedProductCount += len(product.result.Listings)
+	}
+	fmt.Println("Done writing", len(p.products), "products with", p.matchedProductCount, "matched listings to", filename)
+	return nil
+}
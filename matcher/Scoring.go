@@ -0,0 +1,141 @@
+package matcher
+
+import (
+	"math"
+	"slices"
+)
+
+// scoring models for Config.ScoringModel
+const (
+	ScoringModelTokenOrder = "token-order"
+	ScoringModelIDF        = "idf"
+)
+
+// tokenScorer weights product tokens by their inverse document frequency over the products, and optionally over
+// the listings, to turn a candidate's matched tokens and token order difference into a confidence from 0 to 1
+type tokenScorer struct {
+	pt                 *ProductTokens
+	productCount       int
+	listingFrequencies map[string]int // number of listings using each token, nil unless Config.ListingIDF is set
+	listingCount       int
+}
+
+// getTokenWeight returns the inverse document frequency weight of a product token
+func (ts *tokenScorer) getTokenWeight(token *productToken) float64 {
+	weight := math.Log(1 + float64(ts.productCount)/float64(max(len(token.products), 1)))
+	if ts.listingFrequencies != nil {
+		// scaled from 1 for a token no listing uses down to nearly 0 for a token every listing uses
+		weight *= math.Log(float64(ts.listingCount+2)/float64(ts.listingFrequencies[token.value]+1)) / math.Log(float64(ts.listingCount+2))
+	}
+	return weight
+}
+
+// getConfidence returns the share of the product's token weight found in the listing, reduced by the token order
+// difference relative to the highest difference the token order model accepts. Returns 0 if the scorer is nil.
+func (ts *tokenScorer) getConfidence(product *Product, tokenPositions []int, manufacturerAgreement int, tokenOrderDifference int, config *Config) float64 {
+	if ts == nil {
+		return 0
+	}
+	var matchedWeight, totalWeight float64
+	for tokenIndex, tokenObjectIndex := range product.tokenList {
+		weight := ts.getTokenWeight(&ts.pt.tokens[tokenObjectIndex])
+		totalWeight += weight
+		// a manufacturer field agreeing with the product fills in for it's manufacturer tokens
+		if tokenPositions[tokenIndex] >= 0 || tokenIndex < product.manufacturerTokenCount && manufacturerAgreement == manufacturerAgrees {
+			matchedWeight += weight
+		}
+	}
+	coverage := 1.0
+	if totalWeight > 0 {
+		coverage = matchedWeight / totalWeight
+	}
+	return coverage * math.Exp(-float64(tokenOrderDifference)/float64(len(product.tokenList)+config.TokenOrderDifferenceAllowance))
+}
+
+// countListingTokens adds the listings' title tokens to the listing frequencies used by Config.ListingIDF.
+// Not safe to call while listings are being matched.
+func (m *Matcher) countListingTokens(listings []*Listing) {
+	if m.config.ScoringModel != ScoringModelIDF || !m.config.ListingIDF {
+		return
+	}
+	if m.listingFrequencies == nil {
+		m.listingFrequencies = map[string]int{}
+	}
+	for _, listing := range listings {
+		listingTokens := map[string]bool{}
+		for _, listingToken := range m.tokenizers.Title.Tokenize(listing.Title) {
+			listingTokens[listingToken] = true
+		}
+		for listingToken := range listingTokens {
+			m.listingFrequencies[listingToken]++
+		}
+		m.listingCount++
+	}
+}
+
+// getTokenScorer returns the scorer for the configured scoring model, nil for the token order model
+func (m *Matcher) getTokenScorer() *tokenScorer {
+	if m.config.ScoringModel != ScoringModelIDF {
+		return nil
+	}
+	return &tokenScorer{
+		pt:                 m.productTokens,
+		productCount:       m.products.GetProductCount(),
+		listingFrequencies: m.listingFrequencies,
+		listingCount:       m.listingCount,
+	}
+}
+
+// decideByConfidence matches the most confident candidate, unless it's below the minimum confidence or another
+// candidate is within the ambiguity margin of it
+func (lm *listingMatch) decideByConfidence(config *Config) (matchedProduct *Product, tokenOrderDifference int, confidence float64, decision string, competingProducts []*Product) {
+	if len(lm.possibleMatches) == 0 {
+		return nil, 0, 0, DecisionNoCandidates, nil
+	}
+	bestIndex, secondIndex := 0, -1
+	for possibleIndex := 1; possibleIndex < len(lm.possibleMatches); possibleIndex++ {
+		if lm.confidences[possibleIndex] > lm.confidences[bestIndex] {
+			bestIndex, secondIndex = possibleIndex, bestIndex
+		} else if secondIndex < 0 || lm.confidences[possibleIndex] > lm.confidences[secondIndex] {
+			secondIndex = possibleIndex
+		}
+	}
+	bestProduct := lm.possibleMatches[bestIndex]
+	confidence = lm.confidences[bestIndex]
+	if confidence < config.MinConfidence {
+		lm.explanation.decideCandidate(bestProduct, "below minimum confidence")
+		return nil, 0, confidence, DecisionLowConfidence, nil
+	}
+	if secondIndex >= 0 && lm.confidences[secondIndex] >= confidence-config.AmbiguityMargin {
+		// every candidate within the margin competes for the listing
+		competingProducts = []*Product{bestProduct}
+		for possibleIndex, possibleProduct := range lm.possibleMatches {
+			if possibleIndex != bestIndex && lm.confidences[possibleIndex] >= confidence-config.AmbiguityMargin {
+				competingProducts = append(competingProducts, possibleProduct)
+			}
+		}
+		// the most recently announced competing product wins when it's confident enough
+		newestIndex := -1
+		if newestProduct := getNewestProduct(competingProducts); config.PreferRecentProducts && newestProduct != nil {
+			newestIndex = slices.Index(lm.possibleMatches, newestProduct)
+		}
+		if newestIndex < 0 || lm.confidences[newestIndex] < config.MinConfidence {
+			secondProduct := lm.possibleMatches[secondIndex]
+			lm.explanation.decideCandidate(bestProduct, "ambiguous with "+secondProduct.ProductName)
+			lm.explanation.decideCandidate(secondProduct, "ambiguous with "+bestProduct.ProductName)
+			return nil, 0, confidence, DecisionAmbiguous, competingProducts
+		}
+		bestIndex, bestProduct, confidence = newestIndex, lm.possibleMatches[newestIndex], lm.confidences[newestIndex]
+		for _, competingProduct := range competingProducts {
+			if competingProduct != bestProduct {
+				lm.explanation.decideCandidate(competingProduct, "announced before "+bestProduct.ProductName)
+			}
+		}
+	}
+	for _, possibleProduct := range lm.possibleMatches {
+		if possibleProduct != bestProduct && !slices.Contains(competingProducts, possibleProduct) {
+			lm.explanation.decideCandidate(possibleProduct, "less confident than "+bestProduct.ProductName)
+		}
+	}
+	return bestProduct, lm.tokenOrderDifferences[bestIndex], confidence, DecisionMatched, nil
+}
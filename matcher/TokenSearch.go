@@ -0,0 +1,314 @@
+package matcher
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenCandidate is a product token found by a prefix or fuzzy search, with how similar it is to the value searched
+// for, from 0 to 1
+type TokenCandidate struct {
+	Value      string
+	Similarity float64
+	tokenIndex int
+}
+
+// getLevenshteinDistance returns the number of rune insertions, deletions and substitutions needed to turn a into b
+func getLevenshteinDistance(a, b string) int {
+	aRunes, bRunes := []rune(a), []rune(b)
+	previousRow := make([]int, len(bRunes)+1)
+	currentRow := make([]int, len(bRunes)+1)
+	for bIndex := range previousRow {
+		previousRow[bIndex] = bIndex
+	}
+	for aIndex, aRune := range aRunes {
+		currentRow[0] = aIndex + 1
+		for bIndex, bRune := range bRunes {
+			substitutionCost := 1
+			if aRune == bRune {
+				substitutionCost = 0
+			}
+			currentRow[bIndex+1] = min(previousRow[bIndex+1]+1, currentRow[bIndex]+1, previousRow[bIndex]+substitutionCost)
+		}
+		previousRow, currentRow = currentRow, previousRow
+	}
+	return previousRow[len(bRunes)]
+}
+
+// getEditSimilarity turns an edit distance into a similarity from 0 to 1 relative to the longest value
+func getEditSimilarity(a, b string, distance int) float64 {
+	longestLength := max(utf8.RuneCountInString(a), utf8.RuneCountInString(b))
+	if longestLength == 0 {
+		return 1
+	}
+	return 1 - float64(distance)/float64(longestLength)
+}
+
+// bkTreeNode is a token in the BK-tree, it's children are keyed by their edit distance to it. The node keeps the
+// token's value, so that a removed token's node can still be used to search it's children.
+type bkTreeNode struct {
+	value      string
+	tokenIndex int         // -1 once the token has been removed
+	children   map[int]int // edit distance to the index of the child node
+}
+
+// tokenBKTree is a BK-tree of the product tokens, used to find the tokens within an edit distance of a value
+type tokenBKTree struct {
+	nodes []bkTreeNode
+}
+
+// add adds the token to the tree, reusing the node of a removed token with the same value
+func (bk *tokenBKTree) add(value string, tokenIndex int) {
+	if len(bk.nodes) == 0 {
+		bk.nodes = append(bk.nodes, bkTreeNode{value: value, tokenIndex: tokenIndex})
+		return
+	}
+	nodeIndex := 0
+	for {
+		node := &bk.nodes[nodeIndex]
+		distance := getLevenshteinDistance(node.value, value)
+		if distance == 0 {
+			node.tokenIndex = tokenIndex
+			return
+		}
+		childIndex, found := node.children[distance]
+		if !found {
+			if node.children == nil {
+				node.children = map[int]int{}
+			}
+			node.children[distance] = len(bk.nodes)
+			bk.nodes = append(bk.nodes, bkTreeNode{value: value, tokenIndex: tokenIndex})
+			return
+		}
+		nodeIndex = childIndex
+	}
+}
+
+// remove marks the token's node as removed, it's kept in the tree to reach it's children
+func (bk *tokenBKTree) remove(value string) {
+	nodeIndex, found := 0, len(bk.nodes) > 0
+	for found {
+		node := &bk.nodes[nodeIndex]
+		distance := getLevenshteinDistance(node.value, value)
+		if distance == 0 {
+			node.tokenIndex = -1
+			return
+		}
+		nodeIndex, found = node.children[distance]
+	}
+}
+
+// find calls found for every token within maxDistance edits of the value
+func (bk *tokenBKTree) find(value string, maxDistance int, found func(tokenIndex, distance int)) {
+	if len(bk.nodes) == 0 {
+		return
+	}
+	nodesToVisit := []int{0}
+	for len(nodesToVisit) > 0 {
+		node := &bk.nodes[nodesToVisit[len(nodesToVisit)-1]]
+		nodesToVisit = nodesToVisit[:len(nodesToVisit)-1]
+		distance := getLevenshteinDistance(node.value, value)
+		if distance <= maxDistance && node.tokenIndex >= 0 {
+			found(node.tokenIndex, distance)
+		}
+		// by the triangle inequality only the children within maxDistance of this distance can be close enough
+		for childDistance, childIndex := range node.children {
+			if childDistance >= distance-maxDistance && childDistance <= distance+maxDistance {
+				nodesToVisit = append(nodesToVisit, childIndex)
+			}
+		}
+	}
+}
+
+// SearchPrefix returns the tokens starting with the prefix in sorted order, their similarity is the share of the
+// token covered by the prefix. Safe to call from concurrent goroutines.
+func (pt *ProductTokens) SearchPrefix(prefix string) (candidates []TokenCandidate) {
+	pt.tokenIndexes.AscendFrom(prefix, func(tokenValue string, tokenIndex int) bool {
+		if !strings.HasPrefix(tokenValue, prefix) {
+			return false
+		}
+		candidates = append(candidates, TokenCandidate{
+			Value:      tokenValue,
+			Similarity: float64(utf8.RuneCountInString(prefix)) / float64(utf8.RuneCountInString(tokenValue)),
+			tokenIndex: tokenIndex,
+		})
+		return true
+	})
+	return
+}
+
+// minSplitTokenLength is the shortest product token a listing token is split into, so that words aren't split into
+// single letter model tokens
+const minSplitTokenLength = 2
+
+// SearchSplit returns the sequence of product tokens the value is made of, e.g. "sx", "210" and "is" for "sx210is"
+// or "power" and "shot" for "powershot", and nil if it can't be split into at least 2 of them. Longer leading tokens
+// are tried first, their similarity is the share of the value they cover. Safe to call from concurrent goroutines.
+func (pt *ProductTokens) SearchSplit(value string) (candidates []TokenCandidate) {
+	runes := []rune(value)
+	candidates = pt.splitIntoTokens(runes)
+	if len(candidates) < 2 {
+		return nil
+	}
+	for candidateIndex := range candidates {
+		candidates[candidateIndex].Similarity = float64(utf8.RuneCountInString(candidates[candidateIndex].Value)) / float64(len(runes))
+	}
+	return
+}
+
+// splitIntoTokens returns the product tokens the runes are made of, nil if they aren't all product tokens. The
+// longest leading token leaving a remainder that can be split is used. The remainders are split from the shortest
+// up, so that each rune offset is only split once however many ways the tokens overlap, e.g. "ii" and "iii".
+func (pt *ProductTokens) splitIntoTokens(runes []rune) []TokenCandidate {
+	if len(runes) == 0 {
+		return nil
+	}
+	// tokenEnds[start] is where the first token of the remainder starting at start ends, 0 if it can't be split
+	tokenEnds := make([]int, len(runes)+1)
+	tokenIndexes := make([]int, len(runes))
+	tokenEnds[len(runes)] = len(runes)
+	for start := len(runes) - minSplitTokenLength; start >= 0; start-- {
+		for end := len(runes); end >= start+minSplitTokenLength; end-- {
+			if tokenEnds[end] == 0 {
+				continue
+			}
+			tokenIndex := pt.Search(string(runes[start:end]))
+			if tokenIndex < 0 || len(pt.tokens[tokenIndex].products) == 0 {
+				continue
+			}
+			tokenEnds[start], tokenIndexes[start] = end, tokenIndex
+			break
+		}
+	}
+	if tokenEnds[0] == 0 {
+		return nil
+	}
+	candidates := []TokenCandidate{}
+	for start := 0; start < len(runes); start = tokenEnds[start] {
+		candidates = append(candidates, TokenCandidate{Value: string(runes[start:tokenEnds[start]]), tokenIndex: tokenIndexes[start]})
+	}
+	return candidates
+}
+
+// splitListingTokens replaces the listing tokens that aren't product tokens, but are made of several of them,
+// by those product tokens, e.g. "powershotsx" becomes "powershot" and "sx"
+func (lm *listingMatch) splitListingTokens(pt *ProductTokens, config *Config) {
+	splitTokens := make([]string, 0, len(lm.listingTokens))
+	for _, listingToken := range lm.listingTokens {
+		candidates := []TokenCandidate{}
+		if pt.Search(listingToken) < 0 && isFuzzyMatchable(listingToken, config) {
+			candidates = pt.SearchSplit(listingToken)
+		}
+		if len(candidates) == 0 {
+			splitTokens = append(splitTokens, listingToken)
+			continue
+		}
+		for _, candidate := range candidates {
+			splitTokens = append(splitTokens, candidate.Value)
+		}
+	}
+	lm.listingTokens = splitTokens
+}
+
+// SearchFuzzy returns the tokens within maxDistance edits of the value. Safe to call from concurrent goroutines.
+func (pt *ProductTokens) SearchFuzzy(value string, maxDistance int) (candidates []TokenCandidate) {
+	pt.fuzzyIndex.find(value, maxDistance, func(tokenIndex, distance int) {
+		token := &pt.tokens[tokenIndex]
+		candidates = append(candidates, TokenCandidate{
+			Value:      token.value,
+			Similarity: getEditSimilarity(value, token.value, distance),
+			tokenIndex: tokenIndex,
+		})
+	})
+	return
+}
+
+// isFuzzyMatchable returns true if the token can be matched loosely, model numbers and short tokens must match exactly
+func isFuzzyMatchable(token string, config *Config) bool {
+	if utf8.RuneCountInString(token) < config.FuzzyMinTokenLength {
+		return false
+	}
+	return strings.IndexFunc(token, unicode.IsDigit) < 0
+}
+
+// minQuantitySimilarity is the lowest ratio between two quantities of the same unit for them to match loosely,
+// e.g. "mp:12" and "mp:12.1"
+const minQuantitySimilarity = 0.9
+
+// SearchQuantity returns the quantity tokens with the same unit as the quantity token, see ParseQuantityToken, and a
+// value close to it's value. Their similarity is the ratio between the smaller and the larger value.
+// Safe to call from concurrent goroutines.
+func (pt *ProductTokens) SearchQuantity(quantityToken string) (candidates []TokenCandidate) {
+	value, unit, ok := ParseQuantityToken(quantityToken)
+	if !ok {
+		return nil
+	}
+	for _, candidate := range pt.SearchPrefix(unit + quantityTagSeparator) {
+		candidateValue, _, ok := ParseQuantityToken(candidate.Value)
+		if !ok || len(pt.tokens[candidate.tokenIndex].products) == 0 {
+			continue
+		}
+		candidate.Similarity = 1
+		if largestValue := max(value, candidateValue); largestValue > 0 {
+			candidate.Similarity = min(value, candidateValue) / largestValue
+		}
+		if candidate.Similarity >= minQuantitySimilarity {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return
+}
+
+// findFuzzyTokens looks up the listing tokens without an exact product token by prefix and edit distance, and the
+// quantity tokens by value, keeping the best similarity for each product token found, and returns the products
+// using those tokens
+func (lm *listingMatch) findFuzzyTokens(pt *ProductTokens, config *Config) (products []*Product) {
+	lm.fuzzyTokens = make([]map[int]float64, len(lm.listingTokens))
+	for position, listingToken := range lm.listingTokens {
+		if pt.Search(listingToken) >= 0 {
+			continue
+		}
+		candidates := pt.SearchQuantity(listingToken)
+		if isFuzzyMatchable(listingToken, config) {
+			for _, candidate := range append(pt.SearchPrefix(listingToken), pt.SearchFuzzy(listingToken, config.FuzzyMaxEditDistance)...) {
+				if isFuzzyMatchable(candidate.Value, config) {
+					candidates = append(candidates, candidate)
+				}
+			}
+		}
+		for _, candidate := range candidates {
+			if lm.fuzzyTokens[position] == nil {
+				lm.fuzzyTokens[position] = map[int]float64{}
+			}
+			if candidate.Similarity > lm.fuzzyTokens[position][candidate.tokenIndex] {
+				lm.fuzzyTokens[position][candidate.tokenIndex] = candidate.Similarity
+			}
+			products = append(products, pt.tokens[candidate.tokenIndex].products...)
+		}
+	}
+	return
+}
+
+// getTokenSimilarity returns 1 if the listing token at the position is the product token, the similarity found by
+// findFuzzyTokens if it's a loose match, or 0. originalPositions comes from getListingTokensFor.
+func (lm *listingMatch) getTokenSimilarity(listingTokens []string, originalPositions []int, position int, tokenIndex int, token *productToken) float64 {
+	if listingTokens[position] == token.value {
+		return 1
+	}
+	if lm.fuzzyTokens == nil {
+		return 0
+	}
+	if originalPositions != nil {
+		position = originalPositions[position]
+		if position < 0 {
+			return 0
+		}
+	}
+	return lm.fuzzyTokens[position][tokenIndex]
+}
+
+// getFuzzyTokenPenalty returns the token order difference added for a token matched with the given similarity
+func getFuzzyTokenPenalty(similarity float64, config *Config) int {
+	return int((1-similarity)*float64(config.FuzzyTokenPenalty) + 0.5)
+}
@@ -0,0 +1,106 @@
+package matcher
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSearchSplit(t *testing.T) {
+	m := newTestMatcher([]*Product{
+		{ProductName: "Canon_PowerShot_SX210_IS", Manufacturer: "Canon", Family: "PowerShot", Model: "SX210 IS"},
+		{ProductName: "Canon_Power_Shot_A1100_IS", Manufacturer: "Canon", Family: "Power Shot", Model: "A1100 IS"},
+	}, nil)
+	tests := []struct {
+		value  string
+		tokens []string
+	}{
+		{"sx210is", []string{"sx", "210", "is"}},
+		{"powershot", nil}, // a product token on it's own isn't split
+		{"powershotsx", []string{"powershot", "sx"}},
+		{"powershots", nil},
+		{"canonpowershot", []string{"canon", "powershot"}},
+	}
+	for _, test := range tests {
+		var tokens []string
+		for _, candidate := range m.productTokens.SearchSplit(test.value) {
+			tokens = append(tokens, candidate.Value)
+		}
+		if !reflect.DeepEqual(tokens, test.tokens) {
+			t.Errorf("SearchSplit(%q) = %v, expected %v", test.value, tokens, test.tokens)
+		}
+	}
+}
+
+func TestSearchSplitOverlappingTokens(t *testing.T) {
+	pt := newProductTokens()
+	pt.AddTokens(&Product{ProductName: "Roman_II_III"}, []string{"ii", "iii"})
+	if tokens := pt.SearchSplit(strings.Repeat("i", 7)); len(tokens) != 3 || tokens[0].Value != "iii" || tokens[1].Value != "ii" || tokens[2].Value != "ii" {
+		t.Errorf("SearchSplit split 7 i's into %v, expected iii, ii and ii", tokens)
+	}
+	// each offset is only split once, trying every way to split the i's would never end
+	if tokens := pt.SearchSplit(strings.Repeat("i", 500) + "z"); tokens != nil {
+		t.Errorf("SearchSplit split a value ending in a letter that isn't a token into %d tokens", len(tokens))
+	}
+}
+
+func TestSearchFuzzySkipsRemovedProducts(t *testing.T) {
+	m := newTestMatcher([]*Product{
+		{ProductName: "Canon_PowerShot_SX210_IS", Manufacturer: "Canon", Family: "PowerShot", Model: "SX210 IS"},
+		{ProductName: "Olympus_Stylus_Tough_6000", Manufacturer: "Olympus", Family: "Stylus", Model: "Tough 6000"},
+	}, func(config *Config) { config.FuzzyTokens = true })
+	listing := &Listing{Title: "Olympus Stylis Tough 6000"}
+	if matchResult := m.Match(listing); matchResult.Product == nil {
+		t.Fatalf("%q didn't match with FuzzyTokens, decision %q", listing.Title, matchResult.Decision)
+	}
+	m.RemoveProduct(m.Products().products[1])
+	if candidates := m.productTokens.SearchFuzzy("stylis", 1); len(candidates) != 0 {
+		t.Errorf("fuzzy search found %q of the removed product", candidates[0].Value)
+	}
+	if matchResult := m.Match(listing); matchResult.Product != nil {
+		t.Errorf("%q matched the removed product", listing.Title)
+	}
+}
+
+func TestMatchSplitListingTokens(t *testing.T) {
+	products := []*Product{
+		{ProductName: "Canon_PowerShot_SX210_IS", Manufacturer: "Canon", Family: "PowerShot", Model: "SX210 IS"},
+	}
+	listing := &Listing{Title: "Canon PowerShotSX210 IS"}
+	if matchResult := newTestMatcher(products, nil).Match(listing); matchResult.Product != nil {
+		t.Errorf("%q matched without FuzzyTokens", listing.Title)
+	}
+	m := newTestMatcher(products, func(config *Config) { config.FuzzyTokens = true })
+	if matchResult := m.Match(listing); matchResult.Product == nil || matchResult.Product.ProductName != "Canon_PowerShot_SX210_IS" {
+		t.Errorf("%q didn't match with FuzzyTokens, decision %q", listing.Title, matchResult.Decision)
+	}
+}
+
+func TestSearchQuantity(t *testing.T) {
+	pt := newProductTokens()
+	pt.AddTokens(&Product{ProductName: "quantities"}, []string{"mp:12.1", "mp:14", "x:12", "mp"})
+	tests := []struct {
+		value  string
+		tokens []string
+	}{
+		{"mp:12", []string{"mp:12.1"}},
+		{"mp:12.1", []string{"mp:12.1"}},
+		{"mp:13.5", []string{"mp:14"}},
+		{"mp:10", nil},
+		{"x:12.5", []string{"x:12"}},
+		{"mm:12", nil},
+		{"12", nil},
+	}
+	for _, test := range tests {
+		var tokens []string
+		for _, candidate := range pt.SearchQuantity(test.value) {
+			tokens = append(tokens, candidate.Value)
+			if candidate.Similarity < minQuantitySimilarity || candidate.Similarity > 1 {
+				t.Errorf("SearchQuantity(%q) found %q with similarity %v", test.value, candidate.Value, candidate.Similarity)
+			}
+		}
+		if !reflect.DeepEqual(tokens, test.tokens) {
+			t.Errorf("SearchQuantity(%q) = %v, expected %v", test.value, tokens, test.tokens)
+		}
+	}
+}
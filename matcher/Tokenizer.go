@@ -0,0 +1,375 @@
+package matcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Tokenizer turns the value of a field into the tokens used for matching
+type Tokenizer interface {
+	Tokenize(value string) []string
+}
+
+// TokenizerStage is one step of a TokenizerPipeline, transforming the tokens produced by the previous stages
+type TokenizerStage interface {
+	Apply(tokens []string) []string
+}
+
+// TokenizerPipeline is a Tokenizer running it's stages in order, the first stage gets the whole value as a single token
+type TokenizerPipeline struct {
+	stages []TokenizerStage
+}
+
+// NewTokenizerPipeline returns a pipeline running the given stages
+func NewTokenizerPipeline(stages ...TokenizerStage) *TokenizerPipeline {
+	return &TokenizerPipeline{stages: stages}
+}
+
+// Tokenize used by the matcher, runs the value through the stages
+func (tp *TokenizerPipeline) Tokenize(value string) []string {
+	tokens := []string{value}
+	for _, stage := range tp.stages {
+		tokens = stage.Apply(tokens)
+	}
+	return tokens
+}
+
+// tokenizer stage names used in tokenizer config files
+const (
+	TokenizerStageNormalize = "normalize"
+	TokenizerStageSplit     = "split"
+	TokenizerStageStopwords = "stopwords"
+	TokenizerStageSynonyms  = "synonyms"
+	TokenizerStageUnits     = "units"
+	TokenizerStageQuantity  = "quantities"
+)
+
+// normalizeStage lower cases the tokens and folds accents, see foldString
+type normalizeStage struct{}
+
+// Apply used by TokenizerPipeline
+func (normalizeStage) Apply(tokens []string) []string {
+	normalizedTokens := make([]string, len(tokens))
+	for tokenIndex, token := range tokens {
+		normalizedTokens[tokenIndex] = string(foldString(token))
+	}
+	return normalizedTokens
+}
+
+// splitStage splits the tokens into runs of letters and runs of digits, see generateTokensWithUnits. The unit words
+// are the configured units and their aliases, so numbers glued to them like "12,1mpix" are still canonicalized.
+type splitStage struct {
+	unitWords map[string]bool
+}
+
+// Apply used by TokenizerPipeline
+func (ss splitStage) Apply(tokens []string) (splitTokens []string) {
+	for _, token := range tokens {
+		splitTokens = append(splitTokens, generateTokensWithUnits(token, ss.unitWords)...)
+	}
+	return
+}
+
+// stopwordStage removes common words that don't help matching. Words next to a number are kept since they're
+// likely part of a model number, e.g. the "a" in "PowerShot A 1100".
+type stopwordStage struct {
+	stopwords map[string]bool
+}
+
+// Apply used by TokenizerPipeline
+func (ss *stopwordStage) Apply(tokens []string) (keptTokens []string) {
+	for tokenIndex, token := range tokens {
+		if ss.stopwords[token] &&
+			!(tokenIndex > 0 && isNumericToken(tokens[tokenIndex-1])) &&
+			!(tokenIndex+1 < len(tokens) && isNumericToken(tokens[tokenIndex+1])) {
+			continue
+		}
+		keptTokens = append(keptTokens, token)
+	}
+	return
+}
+
+// synonymStage replaces words and phrases with the tokens of their preferred form, longest phrases first
+type synonymStage struct {
+	synonyms        map[string][]string // phrase tokens joined by spaces to the replacement tokens
+	maxPhraseLength int
+}
+
+// newSynonymStage returns a stage replacing the synonyms, the phrases and replacements are tokenized with generateTokensFromString
+func newSynonymStage(synonyms map[string]string) *synonymStage {
+	ss := &synonymStage{synonyms: map[string][]string{}}
+	for phrase, replacement := range synonyms {
+		phraseTokens := generateTokensFromString(phrase)
+		if len(phraseTokens) == 0 {
+			continue
+		}
+		ss.synonyms[strings.Join(phraseTokens, " ")] = generateTokensFromString(replacement)
+		ss.maxPhraseLength = max(ss.maxPhraseLength, len(phraseTokens))
+	}
+	// the preferred forms are kept as they are, so "konica minolta" isn't replaced word by word
+	for _, replacement := range synonyms {
+		replacementTokens := generateTokensFromString(replacement)
+		if _, found := ss.synonyms[strings.Join(replacementTokens, " ")]; !found && len(replacementTokens) > 0 {
+			ss.synonyms[strings.Join(replacementTokens, " ")] = replacementTokens
+			ss.maxPhraseLength = max(ss.maxPhraseLength, len(replacementTokens))
+		}
+	}
+	return ss
+}
+
+// Apply used by TokenizerPipeline
+func (ss *synonymStage) Apply(tokens []string) (replacedTokens []string) {
+	for tokenIndex := 0; tokenIndex < len(tokens); {
+		phraseLength := min(ss.maxPhraseLength, len(tokens)-tokenIndex)
+		for ; phraseLength > 0; phraseLength-- {
+			if replacement, found := ss.synonyms[strings.Join(tokens[tokenIndex:tokenIndex+phraseLength], " ")]; found {
+				replacedTokens = append(replacedTokens, replacement...)
+				tokenIndex += phraseLength
+				break
+			}
+		}
+		if phraseLength == 0 {
+			replacedTokens = append(replacedTokens, tokens[tokenIndex])
+			tokenIndex++
+		}
+	}
+	return
+}
+
+// unitStage replaces the unit following a number with it's canonical name, e.g. "12,1 megapixels" becomes "12,1 mp"
+type unitStage struct {
+	units map[string]string // unit alias to canonical unit
+}
+
+// newUnitStage returns a stage using the canonical units and their aliases
+func newUnitStage(units map[string][]string) *unitStage {
+	us := &unitStage{units: map[string]string{}}
+	for canonicalUnit, aliases := range units {
+		us.units[canonicalUnit] = canonicalUnit
+		for _, alias := range aliases {
+			us.units[string(foldString(alias))] = canonicalUnit
+		}
+	}
+	return us
+}
+
+// Apply used by TokenizerPipeline
+func (us *unitStage) Apply(tokens []string) []string {
+	unitTokens := append([]string{}, tokens...)
+	for tokenIndex := 1; tokenIndex < len(unitTokens); tokenIndex++ {
+		if canonicalUnit, found := us.units[unitTokens[tokenIndex]]; found && isNumericToken(unitTokens[tokenIndex-1]) {
+			unitTokens[tokenIndex] = canonicalUnit
+		}
+	}
+	return unitTokens
+}
+
+// quantityTagSeparator separates the unit from the number in quantity tokens, e.g. "mp:12.1"
+const quantityTagSeparator = ":"
+
+// quantityStage replaces a number followed by one of it's units with a single quantity token tagged with the unit,
+// so "12.1 mp" becomes "mp:12.1". Use after the units stage so the units have their canonical names.
+type quantityStage struct {
+	units map[string]bool
+}
+
+// Apply used by TokenizerPipeline
+func (qs *quantityStage) Apply(tokens []string) (quantityTokens []string) {
+	for tokenIndex := 0; tokenIndex < len(tokens); tokenIndex++ {
+		if tokenIndex+1 < len(tokens) && isNumericToken(tokens[tokenIndex]) && qs.units[tokens[tokenIndex+1]] {
+			quantityTokens = append(quantityTokens, tokens[tokenIndex+1]+quantityTagSeparator+tokens[tokenIndex])
+			tokenIndex++
+			continue
+		}
+		quantityTokens = append(quantityTokens, tokens[tokenIndex])
+	}
+	return
+}
+
+// ParseQuantityToken returns the number and unit of a token made by the quantities stage, so quantities can be
+// compared as numbers. ok is false for other tokens.
+func ParseQuantityToken(token string) (value float64, unit string, ok bool) {
+	unit, number, found := strings.Cut(token, quantityTagSeparator)
+	if !found {
+		return 0, "", false
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return value, unit, true
+}
+
+// defaultStopwords are the stopwords used for a language when the tokenizer config doesn't list any.
+// Words used by the accessory classifier are left out.
+var defaultStopwords = map[string][]string{
+	"en": {"a", "an", "the", "of", "and", "or", "by", "new"},
+	"fr": {"le", "la", "les", "un", "une", "des", "du", "de", "et", "neuf"},
+	"de": {"der", "die", "das", "ein", "eine", "und", "oder", "von", "neu"},
+}
+
+// defaultSynonyms are used when the tokenizer config doesn't list any. The short forms are replaced by the long ones,
+// so use the synonyms stage on the manufacturer field too for titles to agree with the product manufacturers.
+var defaultSynonyms = map[string]string{
+	"fuji":      "fujifilm",
+	"fuji film": "fujifilm",
+	"hp":        "hewlett packard",
+	"konica":    "konica minolta",
+	"minolta":   "konica minolta",
+	"ge":        "general electric",
+	"mp":        "megapixel",
+}
+
+// defaultUnits are used when the tokenizer config doesn't list any
+var defaultUnits = map[string][]string{
+	"mp":   {"megapixel", "megapixels", "mpix", "mpx", "mpixel", "mpixels"},
+	"mm":   {"millimeter", "millimeters", "millimetre", "millimetres"},
+	"gb":   {"go", "gigabyte", "gigabytes"},
+	"x":    {"fach"},
+	"inch": {"inches", "zoll", "pouce", "pouces"},
+}
+
+// defaultQuantityUnits are the units tagged by the quantities stage when the tokenizer config doesn't list any
+var defaultQuantityUnits = []string{"mp", "x", "mm", "inch"}
+
+// TokenizerStageConfig selects a stage in a tokenizer config file
+type TokenizerStageConfig struct {
+	Stage     string   `json:"stage"`
+	Languages []string `json:"languages,omitempty"` // stopword languages for the stopwords stage
+}
+
+// TokenizerConfig is the layout of tokenizer config files. Fields selects the stages used for the "manufacturer",
+// "family", "model" and "title" fields, a missing field uses the default normalize and split stages.
+type TokenizerConfig struct {
+	Stopwords map[string][]string `json:"stopwords"`
+	Synonyms  map[string]string   `json:"synonyms"`
+	Units     map[string][]string `json:"units"`
+	// QuantityUnits are the units tagged by the quantities stage
+	QuantityUnits []string                          `json:"quantity_units"`
+	Fields        map[string][]TokenizerStageConfig `json:"fields"`
+}
+
+// newStage returns the stage selected by the stage config
+func (tc *TokenizerConfig) newStage(stageConfig TokenizerStageConfig) (TokenizerStage, error) {
+	switch stageConfig.Stage {
+	case TokenizerStageNormalize:
+		return normalizeStage{}, nil
+	case TokenizerStageSplit:
+		if tc.Units == nil {
+			return splitStage{unitWords: defaultUnitWords}, nil
+		}
+		return splitStage{unitWords: getUnitWords(tc.Units)}, nil
+	case TokenizerStageStopwords:
+		stopwords := tc.Stopwords
+		if stopwords == nil {
+			stopwords = defaultStopwords
+		}
+		ss := &stopwordStage{stopwords: map[string]bool{}}
+		for _, language := range stageConfig.Languages {
+			languageStopwords, found := stopwords[language]
+			if !found {
+				return nil, fmt.Errorf("no stopwords for language %q", language)
+			}
+			for _, stopword := range languageStopwords {
+				ss.stopwords[string(foldString(stopword))] = true
+			}
+		}
+		return ss, nil
+	case TokenizerStageSynonyms:
+		if tc.Synonyms == nil {
+			return newSynonymStage(defaultSynonyms), nil
+		}
+		return newSynonymStage(tc.Synonyms), nil
+	case TokenizerStageUnits:
+		if tc.Units == nil {
+			return newUnitStage(defaultUnits), nil
+		}
+		return newUnitStage(tc.Units), nil
+	case TokenizerStageQuantity:
+		quantityUnits := tc.QuantityUnits
+		if quantityUnits == nil {
+			quantityUnits = defaultQuantityUnits
+		}
+		qs := &quantityStage{units: map[string]bool{}}
+		for _, unit := range quantityUnits {
+			qs.units[string(foldString(unit))] = true
+		}
+		return qs, nil
+	}
+	return nil, fmt.Errorf("unknown tokenizer stage %q", stageConfig.Stage)
+}
+
+// FieldTokenizers holds the tokenizer used for each field of the products and listings.
+// The listing's manufacturer field uses the Manufacturer tokenizer.
+type FieldTokenizers struct {
+	Manufacturer Tokenizer
+	Family       Tokenizer
+	Model        Tokenizer
+	Title        Tokenizer
+}
+
+// DefaultFieldTokenizers returns the tokenizers the matcher always used, normalizing and splitting every field
+func DefaultFieldTokenizers() *FieldTokenizers {
+	defaultTokenizer := NewTokenizerPipeline(normalizeStage{}, splitStage{unitWords: defaultUnitWords})
+	return &FieldTokenizers{Manufacturer: defaultTokenizer, Family: defaultTokenizer, Model: defaultTokenizer, Title: defaultTokenizer}
+}
+
+// ExtendedTokenizerConfig returns the config used by -extended-tokenizers, running the built in stopwords, synonyms and
+// units without a tokenizer config file. The manufacturer and family fields get the synonyms so they agree with the
+// titles, the model field is left alone so model numbers aren't rewritten.
+func ExtendedTokenizerConfig() *TokenizerConfig {
+	return &TokenizerConfig{Fields: map[string][]TokenizerStageConfig{
+		"manufacturer": {{Stage: TokenizerStageNormalize}, {Stage: TokenizerStageSplit}, {Stage: TokenizerStageSynonyms}},
+		"family":       {{Stage: TokenizerStageNormalize}, {Stage: TokenizerStageSplit}, {Stage: TokenizerStageSynonyms}},
+		"title": {{Stage: TokenizerStageNormalize}, {Stage: TokenizerStageSplit}, {Stage: TokenizerStageStopwords, Languages: []string{"en", "fr", "de"}},
+			{Stage: TokenizerStageSynonyms}, {Stage: TokenizerStageUnits}},
+	}}
+}
+
+// NewFieldTokenizers builds the tokenizers selected by the config
+func NewFieldTokenizers(config *TokenizerConfig) (ft *FieldTokenizers, err error) {
+	ft = DefaultFieldTokenizers()
+	fieldTokenizers := map[string]*Tokenizer{
+		"manufacturer": &ft.Manufacturer, "family": &ft.Family, "model": &ft.Model, "title": &ft.Title,
+	}
+	for field, stageConfigs := range config.Fields {
+		fieldTokenizer, found := fieldTokenizers[field]
+		if !found {
+			return nil, fmt.Errorf("unknown tokenizer field %q", field)
+		}
+		stages := []TokenizerStage{}
+		for _, stageConfig := range stageConfigs {
+			stage, err := config.newStage(stageConfig)
+			if err != nil {
+				return nil, fmt.Errorf("%s field: %v", field, err)
+			}
+			stages = append(stages, stage)
+		}
+		*fieldTokenizer = NewTokenizerPipeline(stages...)
+	}
+	return ft, nil
+}
+
+// LoadTokenizerConfig loads a JSON tokenizer config file and builds the tokenizers it selects
+func LoadTokenizerConfig(filename string) (ft *FieldTokenizers, err error) {
+	configFile, err := os.Open(filename)
+	if err != nil {
+		fmt.Println("Error opening tokenizer config file:", filename, ", error:", err)
+		return nil, err
+	}
+	defer configFile.Close()
+	config := &TokenizerConfig{}
+	if err = json.NewDecoder(configFile).Decode(config); err != nil {
+		fmt.Println("Error decoding tokenizer config file:", filename, ", error:", err)
+		return nil, err
+	}
+	ft, err = NewFieldTokenizers(config)
+	if err != nil {
+		fmt.Println("Error in tokenizer config file:", filename, ", error:", err)
+		return nil, err
+	}
+	return ft, nil
+}
@@ -0,0 +1,180 @@
+package matcher
+
+import (
+	"reflect"
+	"testing"
+)
+
+// readmeConfig is the tokenizer config given as an example in the README
+var readmeConfig = &TokenizerConfig{Fields: map[string][]TokenizerStageConfig{
+	"manufacturer": {{Stage: "normalize"}, {Stage: "split"}, {Stage: "synonyms"}},
+	"title":        {{Stage: "normalize"}, {Stage: "split"}, {Stage: "stopwords", Languages: []string{"en", "fr"}}, {Stage: "synonyms"}, {Stage: "units"}},
+}}
+
+func TestSynonymStage(t *testing.T) {
+	fieldTokenizers, err := NewFieldTokenizers(readmeConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		value  string
+		tokens []string
+	}{
+		{"HP Photosmart R742", []string{"hewlett", "packard", "photosmart", "r", "742"}},
+		{"Hewlett-Packard Photosmart R742", []string{"hewlett", "packard", "photosmart", "r", "742"}},
+		{"Fuji Film FinePix S2500HD", []string{"fujifilm", "finepix", "s", "2500", "hd"}},
+		{"Fuji FinePix S2500HD", []string{"fujifilm", "finepix", "s", "2500", "hd"}},
+		{"Konica Minolta DiMAGE", []string{"konica", "minolta", "dimage"}},
+		{"Minolta DiMAGE", []string{"konica", "minolta", "dimage"}},
+		// "mp" is spelled out by the synonyms, then shortened again by the units stage after a number
+		{"12.1 MP sensor", []string{"12.1", "mp", "sensor"}},
+		{"MP sensor", []string{"megapixel", "sensor"}},
+	}
+	for _, test := range tests {
+		if tokens := fieldTokenizers.Title.Tokenize(test.value); !reflect.DeepEqual(tokens, test.tokens) {
+			t.Errorf("Tokenize(%q) = %q, expected %q", test.value, tokens, test.tokens)
+		}
+	}
+	// the title tokens agree with the product's manufacturer tokens
+	catalog := &Products{}
+	catalog.Add(&Product{ProductName: "HP_Photosmart_R742", Manufacturer: "HP", Family: "Photosmart", Model: "R742"})
+	m := NewMatcherWithTokenizers(catalog, DefaultConfig(), fieldTokenizers)
+	for _, title := range []string{"HP Photosmart R742 7.2MP", "Hewlett Packard Photosmart R742 7.2MP"} {
+		if matchResult := m.Match(&Listing{Title: title}); matchResult.Product == nil || matchResult.TokenOrderDifference != 0 {
+			t.Errorf("%q matched %v with token order difference %d, decision %q", title, matchResult.Product, matchResult.TokenOrderDifference, matchResult.Decision)
+		}
+	}
+}
+
+// newTitleTokenizer returns the title tokenizer running the given stages after normalize and split
+func newTitleTokenizer(t *testing.T, config *TokenizerConfig, stages ...TokenizerStageConfig) Tokenizer {
+	config.Fields = map[string][]TokenizerStageConfig{
+		"title": append([]TokenizerStageConfig{{Stage: "normalize"}, {Stage: "split"}}, stages...),
+	}
+	fieldTokenizers, err := NewFieldTokenizers(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fieldTokenizers.Title
+}
+
+func TestStopwordStage(t *testing.T) {
+	tokenizer := newTitleTokenizer(t, &TokenizerConfig{}, TokenizerStageConfig{Stage: "stopwords", Languages: []string{"en", "fr"}})
+	tests := []struct {
+		value  string
+		tokens []string
+	}{
+		{"The new Canon PowerShot", []string{"canon", "powershot"}},
+		{"Appareil photo de la marque Canon", []string{"appareil", "photo", "marque", "canon"}},
+		// words next to a number are likely part of a model number
+		{"Canon PowerShot A 1100 with a case", []string{"canon", "powershot", "a", "1100", "with", "case"}},
+		{"Canon PowerShot A1100", []string{"canon", "powershot", "a", "1100"}},
+	}
+	for _, test := range tests {
+		if tokens := tokenizer.Tokenize(test.value); !reflect.DeepEqual(tokens, test.tokens) {
+			t.Errorf("Tokenize(%q) = %q, expected %q", test.value, tokens, test.tokens)
+		}
+	}
+	if _, err := NewFieldTokenizers(&TokenizerConfig{Fields: map[string][]TokenizerStageConfig{
+		"title": {{Stage: "stopwords", Languages: []string{"xx"}}},
+	}}); err == nil {
+		t.Error("expected an error for a language without stopwords")
+	}
+}
+
+func TestUnitStage(t *testing.T) {
+	tests := []struct {
+		value  string
+		tokens []string
+	}{
+		{"12.1 megapixels", []string{"12.1", "mp"}},
+		{"12,1MP 5x zoom", []string{"12.1", "mp", "5", "x", "zoom"}},
+		{"2.7 Zoll LCD 18-55 mm", []string{"2.7", "inch", "lcd", "18", "55", "mm"}},
+		{"8 Go SD", []string{"8", "gb", "sd"}},
+		// a unit alias is only a unit after a number
+		{"Megapixel Camera", []string{"megapixel", "camera"}},
+	}
+	tokenizer := newTitleTokenizer(t, &TokenizerConfig{}, TokenizerStageConfig{Stage: "units"})
+	for _, test := range tests {
+		if tokens := tokenizer.Tokenize(test.value); !reflect.DeepEqual(tokens, test.tokens) {
+			t.Errorf("Tokenize(%q) = %q, expected %q", test.value, tokens, test.tokens)
+		}
+	}
+	// the configured units replace the default ones, also for the numbers glued to them
+	tokenizer = newTitleTokenizer(t, &TokenizerConfig{Units: map[string][]string{"mp": {"mégapixels"}}}, TokenizerStageConfig{Stage: "units"})
+	tests = []struct {
+		value  string
+		tokens []string
+	}{
+		{"12,10mégapixels", []string{"12.1", "mp"}},
+		{"12,10 mégapixels", []string{"12.1", "mp"}},
+		{"12,10megapixel", []string{"12,10", "megapixel"}},
+		{"8 Go", []string{"8", "go"}},
+	}
+	for _, test := range tests {
+		if tokens := tokenizer.Tokenize(test.value); !reflect.DeepEqual(tokens, test.tokens) {
+			t.Errorf("configured units Tokenize(%q) = %q, expected %q", test.value, tokens, test.tokens)
+		}
+	}
+}
+
+func TestExtendedTokenizerConfig(t *testing.T) {
+	fieldTokenizers, err := NewFieldTokenizers(ExtendedTokenizerConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens := fieldTokenizers.Title.Tokenize("Die Fuji FinePix S2500HD 12 Megapixel"); !reflect.DeepEqual(tokens, []string{"fujifilm", "finepix", "s", "2500", "hd", "12", "mp"}) {
+		t.Errorf("extended title tokens %q", tokens)
+	}
+	if tokens := fieldTokenizers.Manufacturer.Tokenize("HP"); !reflect.DeepEqual(tokens, []string{"hewlett", "packard"}) {
+		t.Errorf("extended manufacturer tokens %q", tokens)
+	}
+	// model numbers aren't rewritten
+	if tokens := fieldTokenizers.Model.Tokenize("GE 12 MP"); !reflect.DeepEqual(tokens, []string{"ge", "12", "mp"}) {
+		t.Errorf("extended model tokens %q", tokens)
+	}
+}
+
+func TestQuantityStage(t *testing.T) {
+	tests := []struct {
+		value  string
+		tokens []string
+	}{
+		{"12.1 megapixels", []string{"mp:12.1"}},
+		{"12,1MP 5x zoom", []string{"mp:12.1", "x:5", "zoom"}},
+		{"2.7 Zoll LCD 18-55 mm", []string{"inch:2.7", "lcd", "18", "mm:55"}},
+		// gb isn't one of the default quantity units
+		{"8 Go SD", []string{"8", "gb", "sd"}},
+		{"Megapixel Camera", []string{"megapixel", "camera"}},
+	}
+	tokenizer := newTitleTokenizer(t, &TokenizerConfig{}, TokenizerStageConfig{Stage: "units"}, TokenizerStageConfig{Stage: "quantities"})
+	for _, test := range tests {
+		if tokens := tokenizer.Tokenize(test.value); !reflect.DeepEqual(tokens, test.tokens) {
+			t.Errorf("Tokenize(%q) = %q, expected %q", test.value, tokens, test.tokens)
+		}
+	}
+	tokenizer = newTitleTokenizer(t, &TokenizerConfig{QuantityUnits: []string{"gb"}}, TokenizerStageConfig{Stage: "units"}, TokenizerStageConfig{Stage: "quantities"})
+	if tokens := tokenizer.Tokenize("8 Go SD 12 MP"); !reflect.DeepEqual(tokens, []string{"gb:8", "sd", "12", "mp"}) {
+		t.Errorf("configured quantity units tokens %q", tokens)
+	}
+}
+
+func TestParseQuantityToken(t *testing.T) {
+	tests := []struct {
+		token string
+		value float64
+		unit  string
+		ok    bool
+	}{
+		{"mp:12.1", 12.1, "mp", true},
+		{"x:5", 5, "x", true},
+		{"mp:", 0, "", false},
+		{"mp:twelve", 0, "", false},
+		{"12.1", 0, "", false},
+	}
+	for _, test := range tests {
+		if value, unit, ok := ParseQuantityToken(test.token); value != test.value || unit != test.unit || ok != test.ok {
+			t.Errorf("ParseQuantityToken(%q) = %v, %q, %v, expected %v, %q, %v", test.token, value, unit, ok, test.value, test.unit, test.ok)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package matcher
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// foldedLetters lists the accented and ligature letters folded into each plain letter or letters. It covers every
+// lower case letter of the Latin-1 Supplement and Latin Extended-A blocks, TestFoldLatinLetters checks it.
+var foldedLetters = map[string]string{
+	"a": "àáâãäåāăąǎ", "c": "çćĉċč", "d": "ďđð", "e": "èéêëēĕėęě", "g": "ĝğġģ", "h": "ĥħ",
+	"i": "ìíîïĩīĭįı", "j": "ĵ", "k": "ķĸ", "l": "ĺļľŀł", "n": "ñńņňŉ", "o": "òóôõöøōŏőǒ",
+	"r": "ŕŗř", "s": "śŝşšșſ", "t": "ţťŧț", "u": "ùúûüũūŭůűųǔ", "w": "ŵ", "y": "ýÿŷ", "z": "źżž",
+	"ss": "ß", "ae": "æ", "oe": "œ", "th": "þ", "ij": "ĳ", "ng": "ŋ",
+}
+
+// foldTable maps each lower case accented or ligature letter to it's plain form, built from foldedLetters. It's
+// built as a variable rather than in init so the other package variables like defaultUnitWords can fold strings.
+var foldTable = getFoldTable()
+
+func getFoldTable() map[rune]string {
+	foldTable := map[rune]string{}
+	for plainLetters, accentedLetters := range foldedLetters {
+		for _, accentedLetter := range accentedLetters {
+			foldTable[accentedLetter] = plainLetters
+		}
+	}
+	return foldTable
+}
+
+// foldString lower cases the value, folds accented letters and ligatures to plain letters, turns full-width
+// letters and digits into their ASCII form, and drops combining marks left over from decomposed accents
+func foldString(value string) (folded []rune) {
+	folded = make([]rune, 0, len(value))
+	for _, r := range value {
+		// full-width forms are offset from ASCII by a fixed amount
+		if r >= '！' && r <= '～' {
+			r = r - '！' + '!'
+		}
+		r = unicode.ToLower(r)
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if plainLetters, found := foldTable[r]; found {
+			folded = append(folded, []rune(plainLetters)...)
+			continue
+		}
+		folded = append(folded, r)
+	}
+	return
+}
+
+// breaks a string up into 'tokens' for matching. Used by Products.go and Matcher.go
+// tokens are runs of letters or runs of digits, numbers keep their decimal separators
+func generateTokensFromString(value string) (tokens []string) {
+	return generateTokensWithUnits(value, defaultUnitWords)
+}
+
+// generateTokensWithUnits is generateTokensFromString with the unit words numbers can be glued to, see isStandaloneNumber
+func generateTokensWithUnits(value string, unitWords map[string]bool) (tokens []string) {
+	runes := foldString(value)
+	token := []rune{}
+	tokenStart := 0
+	var tokenIsNumeric bool
+	endToken := func(end int) {
+		if len(token) == 0 {
+			return
+		}
+		tokenValue := string(token)
+		if tokenIsNumeric && isStandaloneNumber(runes, tokenStart, end, unitWords) {
+			tokenValue = canonicalizeNumber(tokenValue)
+		}
+		tokens = append(tokens, tokenValue)
+		token = token[:0]
+	}
+	for i, r := range runes {
+		isLetter, isDigit := unicode.IsLetter(r), unicode.IsDigit(r)
+		if tokenIsNumeric && len(token) > 0 && (r == ',' || r == '.') && i+1 < len(runes) && unicode.IsDigit(runes[i+1]) {
+			token = append(token, r)
+			continue
+		}
+		if len(token) > 0 && (!isLetter && !isDigit || isDigit != tokenIsNumeric) {
+			endToken(i)
+		}
+		if isLetter || isDigit {
+			if len(token) == 0 {
+				tokenStart = i
+			}
+			token = append(token, r)
+			tokenIsNumeric = isDigit
+		}
+	}
+	endToken(len(runes))
+	return
+}
+
+// defaultUnitWords are the default units and their aliases, see defaultUnits
+var defaultUnitWords = getUnitWords(defaultUnits)
+
+// getUnitWords returns the canonical units and their aliases, accent folded
+func getUnitWords(units map[string][]string) map[string]bool {
+	unitWords := map[string]bool{}
+	for unit, aliases := range units {
+		unitWords[string(foldString(unit))] = true
+		for _, alias := range aliases {
+			unitWords[string(foldString(alias))] = true
+		}
+	}
+	return unitWords
+}
+
+// isStandaloneNumber returns false when the number runes[start:end] is glued to letters, so it's part of a model
+// number like the "1.050" of "EX-Z1.050", unless those letters are one of the unit words following it, as in "12,1mp"
+func isStandaloneNumber(runes []rune, start, end int, unitWords map[string]bool) bool {
+	if start > 0 && unicode.IsLetter(runes[start-1]) {
+		return false
+	}
+	letterEnd := end
+	for letterEnd < len(runes) && unicode.IsLetter(runes[letterEnd]) {
+		letterEnd++
+	}
+	return letterEnd == end || unitWords[string(runes[end:letterEnd])]
+}
+
+// canonicalizeNumber writes standalone numbers with separators the same way whatever their locale, "12,1" and "12.10" become
+// "12.1", "1,000,000" and "1.000,0" become "1000000" and "1000". Tokens without separators, or with separators that can't
+// be told apart, are returned as they are. That includes a single separator followed by 3 digits, "1.299" could be a
+// thousand or a version number.
+func canonicalizeNumber(token string) string {
+	separators := []rune{}
+	for _, r := range token {
+		if r == '.' || r == ',' {
+			separators = append(separators, r)
+		}
+	}
+	if len(separators) == 0 {
+		return token
+	}
+	groups := strings.FieldsFunc(token, func(r rune) bool { return r == '.' || r == ',' })
+	if len(groups) != len(separators)+1 {
+		return token
+	}
+	lastSeparator := separators[len(separators)-1]
+	if len(separators) == 1 && len(groups[1]) == 3 && groups[0] != "0" {
+		return token
+	}
+	// the last separator is a decimal separator when it differs from the others, or when it's used once
+	lastIsDecimal := len(separators) > 1 && separators[0] != lastSeparator || len(separators) == 1
+	thousandsGroupCount := len(groups) - 1
+	if lastIsDecimal {
+		thousandsGroupCount--
+	}
+	for groupIndex := 1; groupIndex <= thousandsGroupCount; groupIndex++ {
+		if len(groups[groupIndex]) != 3 || separators[groupIndex-1] != separators[0] {
+			return token
+		}
+	}
+	number := strings.Join(groups[:thousandsGroupCount+1], "")
+	if lastIsDecimal {
+		number += "." + groups[len(groups)-1]
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return token
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// maxJoinedTokenCount is the most adjacent tokens joined together into a model number form
+const maxJoinedTokenCount = 4
+
+// joinedToken is the concatenation of the tokens from start up to, but not including, end
+type joinedToken struct {
+	value string
+	start int
+	end   int
+}
+
+// isNumericToken returns true for the tokens made of digits, see generateTokensFromString
+func isNumericToken(token string) bool {
+	return strings.IndexFunc(token, unicode.IsDigit) == 0
+}
+
+// generateJoinedTokens returns the canonical joined forms of adjacent letter and digit tokens, so that a model number
+// written "DSC-W310", "DSCW310" or "dsc w 310" has the same "dscw310" form. Only joins of both letters and digits are returned.
+func generateJoinedTokens(tokens []string) (joinedTokens []joinedToken) {
+	for start := range tokens {
+		value := tokens[start]
+		hasLetters, hasDigits := !isNumericToken(tokens[start]), isNumericToken(tokens[start])
+		for end := start + 1; end < len(tokens) && end-start < maxJoinedTokenCount; end++ {
+			value += tokens[end]
+			if isNumericToken(tokens[end]) {
+				hasDigits = true
+			} else {
+				hasLetters = true
+			}
+			if hasLetters && hasDigits {
+				joinedTokens = append(joinedTokens, joinedToken{value: value, start: start, end: end + 1})
+			}
+		}
+	}
+	return
+}
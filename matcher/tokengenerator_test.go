@@ -0,0 +1,89 @@
+package matcher
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestGenerateTokensFromString(t *testing.T) {
+	tests := []struct {
+		value  string
+		tokens []string
+	}{
+		{"DSC-W310", []string{"dsc", "w", "310"}},
+		{"Appareil photo numérique", []string{"appareil", "photo", "numerique"}},
+		{"Digitalkamera für Straße", []string{"digitalkamera", "fur", "strasse"}},
+		{"ＤＳＣ－Ｗ３１０", []string{"dsc", "w", "310"}},
+		{"Cœur", []string{"coeur"}},
+		// standalone numbers are canonicalized
+		{"12,1 Megapixels", []string{"12.1", "megapixels"}},
+		{"12.10 MP", []string{"12.1", "mp"}},
+		{"12,1MP", []string{"12.1", "mp"}},
+		{"1.000,0 mm", []string{"1000", "mm"}},
+		{"version 1.2.3", []string{"version", "1.2.3"}},
+		// numbers that are part of a model number are left alone
+		{"EX-Z1.050", []string{"ex", "z", "1.050"}},
+		{"1.050D", []string{"1.050", "d"}},
+	}
+	for _, test := range tests {
+		if tokens := generateTokensFromString(test.value); !reflect.DeepEqual(tokens, test.tokens) {
+			t.Errorf("generateTokensFromString(%q) = %q, expected %q", test.value, tokens, test.tokens)
+		}
+	}
+}
+
+func TestFoldLatinLetters(t *testing.T) {
+	for r := rune(0xC0); r <= 0x17F; r++ {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		folded := string(foldString(string(r)))
+		if strings.IndexFunc(folded, func(foldedRune rune) bool { return foldedRune < 'a' || foldedRune > 'z' }) >= 0 {
+			t.Errorf("%U %c folded to %q, expected plain lower case letters", r, r, folded)
+		}
+		if upperFolded := string(foldString(string(unicode.ToUpper(r)))); upperFolded != folded {
+			t.Errorf("%U %c folded to %q but it's upper case to %q", r, r, folded, upperFolded)
+		}
+	}
+	// decomposed accents fold the same as the composed letters
+	if folded := string(foldString("Nume\u0301rique")); folded != "numerique" {
+		t.Errorf("decomposed accent folded to %q", folded)
+	}
+}
+
+func TestCanonicalizeNumber(t *testing.T) {
+	tests := map[string]string{
+		"12":        "12",
+		"12,1":      "12.1",
+		"12.10":     "12.1",
+		"12.0":      "12",
+		"1,000,000": "1000000",
+		"1.000.000": "1000000",
+		// a single separator followed by 3 digits can be a thousands separator or part of a version
+		"1,000":    "1,000",
+		"1.299":    "1.299",
+		"0.299":    "0.299",
+		"1.000,0":  "1000",
+		"1,299.50": "1299.5",
+		"1.2.3":    "1.2.3",
+		"1,2.3,4":  "1,2.3,4",
+	}
+	for token, expected := range tests {
+		if canonicalized := canonicalizeNumber(token); canonicalized != expected {
+			t.Errorf("canonicalizeNumber(%q) = %q, expected %q", token, canonicalized, expected)
+		}
+	}
+}
+
+func TestGenerateJoinedTokens(t *testing.T) {
+	joinedValues := []string{}
+	for _, joinedToken := range generateJoinedTokens([]string{"sony", "dsc", "w", "310"}) {
+		joinedValues = append(joinedValues, joinedToken.value)
+	}
+	expected := []string{"sonydscw310", "dscw310", "w310"}
+	if !reflect.DeepEqual(joinedValues, expected) {
+		t.Errorf("generateJoinedTokens = %q, expected %q", joinedValues, expected)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Scalu/sortablechallenge/matcher"
+	"github.com/Scalu/sortablechallenge/sortablechallengeutils"
+)
+
+// options holds the command line settings, which can also be loaded from a JSON config file
+type options struct {
+	ArchiveFileName    string         `json:"archive"`
+	ArchiveSourceURL   string         `json:"archive_url"`
+	ProductsFileName   string         `json:"products"`
+	ListingsFileName   string         `json:"listings"`
+	ResultsFileName    string         `json:"results"`
+	UnmatchedFile      string         `json:"unmatched"`
+	ExplainFileName    string         `json:"explain"`
+	CandidatesFile     string         `json:"candidates"`
+	AmbiguousFile      string         `json:"ambiguous"`
+	CurrencyRates      string         `json:"currency_rates"`
+	TokenizerConfig    string         `json:"tokenizer_config"`
+	ExtendedTokenizers bool           `json:"extended_tokenizers"`
+	Stream             bool           `json:"stream"`
+	Matcher            matcher.Config `json:"matcher"`
+}
+
+// registerFlags binds the options to flags in the given flag set
+func (o *options) registerFlags(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&o.ArchiveFileName, "archive", o.ArchiveFileName, "tar.gz archive the input files are extracted from when they are missing")
+	flagSet.StringVar(&o.ArchiveSourceURL, "archive-url", o.ArchiveSourceURL, "URL the archive is downloaded from when it is missing")
+	flagSet.StringVar(&o.ProductsFileName, "products", o.ProductsFileName, "products input file")
+	flagSet.StringVar(&o.ListingsFileName, "listings", o.ListingsFileName, "listings input file")
+	flagSet.StringVar(&o.ResultsFileName, "results", o.ResultsFileName, "results output file")
+	flagSet.StringVar(&o.UnmatchedFile, "unmatched", o.UnmatchedFile, "unmatched listings output file")
+	flagSet.StringVar(&o.CurrencyRates, "currency-rates", o.CurrencyRates, "exchange rates file, ECB style XML if it ends in .xml or CSV with date,currency,units per USD records")
+	flagSet.StringVar(&o.TokenizerConfig, "tokenizer-config", o.TokenizerConfig, "JSON file selecting the tokenizer stages, stopwords, synonyms and units used for each field")
+	flagSet.BoolVar(&o.ExtendedTokenizers, "extended-tokenizers", o.ExtendedTokenizers, "use the built in stopwords, synonyms and units stages without a tokenizer config file, ignored with -tokenizer-config")
+	flagSet.BoolVar(&o.Stream, "stream", o.Stream, "match listings while they are decoded instead of loading them all in memory first")
+	flagSet.StringVar(&o.ExplainFileName, "explain", o.ExplainFileName, "optional output file explaining each listing's match or rejection, one JSON object per line")
+	flagSet.StringVar(&o.CandidatesFile, "candidates", o.CandidatesFile, "optional output file with the top candidate products of each listing and the rule that picked or rejected each one, one JSON object per line")
+	flagSet.StringVar(&o.AmbiguousFile, "ambiguous", o.AmbiguousFile, "optional output file for the listings left unmatched because several products matched them equally well, with the competing products, instead of the unmatched listings file")
+	flagSet.IntVar(&o.Matcher.TopCandidates, "top-candidates", o.Matcher.TopCandidates, "number of candidates written for each listing with -candidates")
+	flagSet.IntVar(&o.Matcher.MaxTokenOrderDifference, "max-token-order-difference", o.Matcher.MaxTokenOrderDifference, "token order difference above which a candidate is never matched")
+	flagSet.IntVar(&o.Matcher.TokenOrderDifferenceAllowance, "token-order-allowance", o.Matcher.TokenOrderDifferenceAllowance, "allowance added to a product's token count to get it's highest acceptable token order difference")
+	flagSet.Float64Var(&o.Matcher.MaxPriceRangeSpread, "max-price-spread", o.Matcher.MaxPriceRangeSpread, "ratio between the highest and lowest price of a product's accepted price range")
+	flagSet.Float64Var(&o.Matcher.PriceVarianceFactor, "price-variance", o.Matcher.PriceVarianceFactor, "factor applied to a listing's weight to get how far out of the price range it may be")
+	flagSet.IntVar(&o.Matcher.ManufacturerConflictPenalty, "manufacturer-conflict-penalty", o.Matcher.ManufacturerConflictPenalty, "token order difference added when the listing's manufacturer field names another manufacturer, negative to veto the match, 0 to ignore the field")
+	flagSet.StringVar(&o.Matcher.PriceOutlierStrategy, "price-outliers", o.Matcher.PriceOutlierStrategy, "price outlier strategy, \"range\" for the original best price range or \"mad\" for median absolute deviation of log prices")
+	flagSet.Float64Var(&o.Matcher.PriceOutlierThreshold, "price-outlier-threshold", o.Matcher.PriceOutlierThreshold, "deviations from the median log price past which the mad strategy drops a listing")
+	flagSet.BoolVar(&o.Matcher.PriceStatistics, "price-stats", o.Matcher.PriceStatistics, "add USD price statistics and the accepted price band to each product's results, extending the challenge's results format")
+	flagSet.BoolVar(&o.Matcher.JoinModelNumbers, "join-model-numbers", o.Matcher.JoinModelNumbers, "match model numbers regardless of the hyphens and spaces between their letters and digits")
+	flagSet.BoolVar(&o.Matcher.FuzzyTokens, "fuzzy-tokens", o.Matcher.FuzzyTokens, "give partial credit to listing tokens that are a prefix of, or a few edits away from, a product token, and split listing tokens made of several product tokens")
+	flagSet.IntVar(&o.Matcher.FuzzyMaxEditDistance, "fuzzy-max-edit-distance", o.Matcher.FuzzyMaxEditDistance, "edits allowed between a listing token and a product token with -fuzzy-tokens")
+	flagSet.IntVar(&o.Matcher.FuzzyMinTokenLength, "fuzzy-min-token-length", o.Matcher.FuzzyMinTokenLength, "shortest token matched loosely with -fuzzy-tokens")
+	flagSet.IntVar(&o.Matcher.FuzzyTokenPenalty, "fuzzy-token-penalty", o.Matcher.FuzzyTokenPenalty, "token order difference added for a loosely matched token, scaled by how dissimilar it is")
+	flagSet.StringVar(&o.Matcher.ScoringModel, "scoring", o.Matcher.ScoringModel, "scoring model, \"token-order\" for the original token order difference or \"idf\" for confidence from inverse document frequency weighted tokens")
+	flagSet.Float64Var(&o.Matcher.MinConfidence, "min-confidence", o.Matcher.MinConfidence, "lowest confidence matched by the idf scoring model")
+	flagSet.Float64Var(&o.Matcher.AmbiguityMargin, "ambiguity-margin", o.Matcher.AmbiguityMargin, "confidence margin the best candidate needs over the second best with the idf scoring model")
+	flagSet.BoolVar(&o.Matcher.ListingIDF, "listing-idf", o.Matcher.ListingIDF, "also weight tokens by their inverse document frequency over the listings with the idf scoring model")
+	flagSet.IntVar(&o.Matcher.Workers, "workers", o.Matcher.Workers, "number of goroutines matching listings, 0 for one per CPU")
+	flagSet.IntVar(&o.Matcher.AnachronismPenalty, "anachronism-penalty", o.Matcher.AnachronismPenalty, "token order difference added when a listing's date is before the product's announced date, negative to veto the match, 0 to ignore the dates")
+	flagSet.BoolVar(&o.Matcher.PreferRecentProducts, "prefer-recent", o.Matcher.PreferRecentProducts, "match the most recently announced of the products that would otherwise make a listing ambiguous")
+	flagSet.BoolVar(&o.Matcher.DetectAccessories, "detect-accessories", o.Matcher.DetectAccessories, "keep listings classified as accessories out of the product results")
+}
+
+// loadConfigFile overwrites the options with the values found in a JSON config file
+func (o *options) loadConfigFile(fileName string) (err error) {
+	configFile, err := os.Open(fileName)
+	if err != nil {
+		fmt.Println("Error opening config file:", fileName, ", error:", err)
+		return err
+	}
+	defer configFile.Close()
+	if err = json.NewDecoder(configFile).Decode(o); err != nil {
+		fmt.Println("Error decoding config file:", fileName, ", error:", err)
+	}
+	return err
+}
+
+// defaultTopCandidates is the number of candidates written for each listing by -candidates
+const defaultTopCandidates = 3
+
+// parseOptions returns the options built from the defaults, the optional config file and the command line flags, in that order of precedence.
+// registerCommandFlags can add the flags specific to a command, it may be nil.
+func parseOptions(name string, arguments []string, registerCommandFlags func(*flag.FlagSet)) (o *options, err error) {
+	o = &options{
+		ArchiveFileName:  "challenge_data_20110429.tar.gz",
+		ArchiveSourceURL: "https://s3.amazonaws.com/sortable-public/challenge/challenge_data_20110429.tar.gz",
+		ProductsFileName: "products.txt",
+		ListingsFileName: "listings.txt",
+		ResultsFileName:  "results.txt",
+		UnmatchedFile:    "unmatched.txt",
+		Matcher:          matcher.DefaultConfig(),
+	}
+	o.Matcher.TopCandidates = defaultTopCandidates
+	flagSet := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFileName := flagSet.String("config", "", "JSON config file, command line flags take precedence over it's values")
+	o.registerFlags(flagSet)
+	if registerCommandFlags != nil {
+		registerCommandFlags(flagSet)
+	}
+	if err = flagSet.Parse(arguments); err != nil {
+		return nil, err
+	}
+	if *configFileName != "" {
+		if err = o.loadConfigFile(*configFileName); err != nil {
+			return nil, err
+		}
+		// parse the flags again so that they override the config file
+		if err = flagSet.Parse(arguments); err != nil {
+			return nil, err
+		}
+	}
+	if err = o.Matcher.Validate(); err != nil {
+		fmt.Println("Error in the matcher options:", err)
+		return nil, err
+	}
+	return o, nil
+}
+
+// getArchive returns the archive the input files are extracted from
+func (o *options) getArchive() *sortablechallengeutils.JSONArchive {
+	return &sortablechallengeutils.JSONArchive{ArchiveFileName: o.ArchiveFileName, ArchiveSourceURL: o.ArchiveSourceURL}
+}
+
+// getMatcher returns a matcher for the products using the configured thresholds, tokenizers and exchange rates
+func (o *options) getMatcher(products *matcher.Products) (productMatcher *matcher.Matcher, err error) {
+	tokenizers := matcher.DefaultFieldTokenizers()
+	if o.TokenizerConfig != "" {
+		tokenizers, err = matcher.LoadTokenizerConfig(o.TokenizerConfig)
+		if err != nil {
+			return nil, err
+		}
+	} else if o.ExtendedTokenizers {
+		if tokenizers, err = matcher.NewFieldTokenizers(matcher.ExtendedTokenizerConfig()); err != nil {
+			fmt.Println("Error in the extended tokenizers:", err)
+			return nil, err
+		}
+	}
+	productMatcher = matcher.NewMatcherWithTokenizers(products, o.getMatcherConfig(), tokenizers)
+	if o.CurrencyRates != "" {
+		currencyRates, err := matcher.LoadCurrencyRates(o.CurrencyRates)
+		if err != nil {
+			return nil, err
+		}
+		productMatcher.SetCurrencyRates(currencyRates)
+	}
+	return productMatcher, nil
+}
+
+// getMatcherConfig returns the matcher configuration with the settings implied by the output options
+func (o *options) getMatcherConfig() matcher.Config {
+	config := o.Matcher
+	config.Explain = config.Explain || o.ExplainFileName != ""
+	// the candidates are only kept when they are written out
+	if o.CandidatesFile == "" {
+		config.TopCandidates = 0
+	}
+	return config
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Scalu/sortablechallenge/matcher"
+)
+
+func TestParseOptions(t *testing.T) {
+	o, err := parseOptions("test", []string{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.ProductsFileName != "products.txt" || o.ListingsFileName != "listings.txt" || o.Matcher.MaxTokenOrderDifference != 50 ||
+		o.Matcher.TokenOrderDifferenceAllowance != 2 || o.Matcher.MaxPriceRangeSpread != 2.0 || o.Matcher.PriceVarianceFactor != 0.05 {
+		t.Errorf("unexpected defaults %+v", o)
+	}
+	// the config file overrides the defaults and the flags override the config file
+	configFileName := filepath.Join(t.TempDir(), "config.json")
+	config := `{"products": "catalog.txt", "results": "out.txt", "matcher": {"max_price_range_spread": 2.5, "max_token_order_difference": 40}}`
+	if err = os.WriteFile(configFileName, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	o, err = parseOptions("test", []string{"-config", configFileName, "-results", "flag.txt", "-max-token-order-difference", "30"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.ProductsFileName != "catalog.txt" || o.Matcher.MaxPriceRangeSpread != 2.5 {
+		t.Errorf("config file values not loaded: %+v", o)
+	}
+	if o.ResultsFileName != "flag.txt" || o.Matcher.MaxTokenOrderDifference != 30 {
+		t.Errorf("flags didn't override the config file: %+v", o)
+	}
+	if o.ListingsFileName != "listings.txt" {
+		t.Errorf("listings file %q, expected the default", o.ListingsFileName)
+	}
+	if _, err = parseOptions("test", []string{"-config", filepath.Join(t.TempDir(), "missing.json")}, nil); err == nil {
+		t.Error("expected an error for a missing config file")
+	}
+	if _, err = parseOptions("test", []string{"-unknown-flag"}, nil); err == nil {
+		t.Error("expected an error for an unknown flag")
+	}
+	// a misspelled strategy isn't replaced by the default one
+	if _, err = parseOptions("test", []string{"-price-outliers", "MAD"}, nil); err == nil {
+		t.Error("expected an error for an unknown price outlier strategy")
+	}
+	if o, err = parseOptions("test", []string{"-price-outliers", "mad"}, nil); err != nil || o.Matcher.PriceOutlierStrategy != matcher.PriceOutlierStrategyMAD {
+		t.Errorf("mad price outlier strategy not accepted: %v", err)
+	}
+	if _, err = parseOptions("test", []string{"-scoring", "IDF"}, nil); err == nil {
+		t.Error("expected an error for an unknown scoring model")
+	}
+	if o, err = parseOptions("test", []string{"-scoring", "idf"}, nil); err != nil || o.Matcher.ScoringModel != matcher.ScoringModelIDF {
+		t.Errorf("idf scoring model not accepted: %v", err)
+	}
+}
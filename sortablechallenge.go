@@ -5,35 +5,106 @@ import (
 	"os"
 	"time"
 
-	"github.com/Scalu/sortablechallenge/sortablechallengeutils"
+	"github.com/Scalu/sortablechallenge/matcher"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "evaluate" {
+		os.Exit(runEvaluate(os.Args[0]+" evaluate", os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "lint-catalog" {
+		os.Exit(runLintCatalog(os.Args[0]+" lint-catalog", os.Args[2:]))
+	}
+	o, err := parseOptions(os.Args[0], os.Args[1:], nil)
+	if err != nil {
+		os.Exit(2)
+	}
 	startTime := time.Now()
 	fmt.Println("Begining sortedchallenge program at", startTime)
-	defer fmt.Println("Exiting sortedchallenge. Duration:", time.Since(startTime))
-	// load the products and listings data
-	products := Products{}
-	listings := Listings{}
-	archive := sortablechallengeutils.JSONArchive{ArchiveFileName: "challenge_data_20110429.tar.gz", ArchiveSourceURL: "https://s3.amazonaws.com/sortable-public/challenge/challenge_data_20110429.tar.gz"}
+	exitCode := runMatch(o)
+	fmt.Println("Exiting sortedchallenge. Duration:", time.Since(startTime))
+	os.Exit(exitCode)
+}
+
+// runMatch matches the listings to the products and exports the results, returns the exit code
+func runMatch(o *options) int {
+	// load the products data
+	products := matcher.Products{FileName: o.ProductsFileName}
+	archive := o.getArchive()
 	err := archive.ImportJSONFromArchiveFile(&products)
 	if err != nil {
 		fmt.Println("Error importing products data:", err)
-		os.Exit(1)
+		return 1
+	}
+	// generate product signatures
+	productMatcher, err := o.getMatcher(&products)
+	if err != nil {
+		return 1
+	}
+	if o.Stream {
+		// match the listings while they are being decoded
+		listingStream, err := matcher.NewListingStream(productMatcher, o.UnmatchedFile, o.ExplainFileName)
+		if err != nil {
+			return 1
+		}
+		listingStream.FileName = o.ListingsFileName
+		if o.CandidatesFile != "" {
+			if err = listingStream.SetCandidatesFile(o.CandidatesFile); err != nil {
+				listingStream.Close()
+				return 1
+			}
+		}
+		if o.AmbiguousFile != "" {
+			if err = listingStream.SetAmbiguousFile(o.AmbiguousFile); err != nil {
+				listingStream.Close()
+				return 1
+			}
+		}
+		err = archive.ImportJSONFromArchiveFile(listingStream)
+		if closeErr := listingStream.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			fmt.Println("Error streaming listings data:", err)
+			return 1
+		}
+		if err = products.ExportResults(o.ResultsFileName); err != nil {
+			return 1
+		}
+		fmt.Println("Listing outcomes:", listingStream.GetOutcomeCounts())
+		return 0
 	}
+	// load the listings data and match them to the products
+	listings := matcher.Listings{FileName: o.ListingsFileName, ExcludeAmbiguous: o.AmbiguousFile != ""}
 	err = archive.ImportJSONFromArchiveFile(&listings)
 	if err != nil {
 		fmt.Println("Error importing listings data:", err)
-		os.Exit(1)
+		return 1
 	}
-	fmt.Println("Done loading JSON data.", len(products.products), "products,", len(listings.listings), "listings")
-	// generate product signatures
-	productTokens := products.GetTokens()
-	// map listings to signatures
-	listings.MapToProducts(productTokens)
-	// weed out price abberations
-	products.dropIrregularlyPricedResults()
+	fmt.Println("Done loading JSON data.", products.GetProductCount(), "products,", listings.GetListingCount(), "listings")
+	productMatcher.MatchListings(&listings)
 	// export results
-	listings.exportUnmatchedListings("unmatched.txt")
-	products.exportResults("results.txt")
+	if err = listings.ExportUnmatchedListings(o.UnmatchedFile); err != nil {
+		return 1
+	}
+	if err = products.ExportResults(o.ResultsFileName); err != nil {
+		return 1
+	}
+	if o.ExplainFileName != "" {
+		if err = listings.ExportExplanations(o.ExplainFileName); err != nil {
+			return 1
+		}
+	}
+	if o.CandidatesFile != "" {
+		if err = listings.ExportCandidates(o.CandidatesFile); err != nil {
+			return 1
+		}
+	}
+	if o.AmbiguousFile != "" {
+		if err = listings.ExportAmbiguousListings(o.AmbiguousFile); err != nil {
+			return 1
+		}
+	}
+	fmt.Println("Listing outcomes:", listings.GetOutcomeCounts())
+	return 0
 }
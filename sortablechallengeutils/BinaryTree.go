@@ -1,5 +1,7 @@
 package sortablechallengeutils
 
+import "fmt"
+
 // BinaryTreeComparer This interface lists the methods required by the BinaryTree code for it's comparer
 type BinaryTreeComparer interface {
 	// BinaryTreeCompare needs to return -1, 0, or 1 depending on the comparison between the two indexed values
@@ -15,9 +17,8 @@ type binaryTreeNode struct {
 	leftright [2]*binaryTreeNode
 }
 
-// BinaryTree incomplete weight-balanced tree implementation
-// does not have delete functionality since it's not required for the current program
-// the delete functionality can be implemented later if need be
+// BinaryTree weight-balanced tree implementation
+// a node's weight is the number of nodes on it's right side minus the number of nodes on it's left side
 type BinaryTree struct {
 	rootNode       *binaryTreeNode
 	rebalanceList  []*binaryTreeNode
@@ -131,6 +132,15 @@ func (bt *BinaryTree) processRebalanceList(comparer BinaryTreeComparer) {
 	bt.rebalanceList = []*binaryTreeNode{}
 }
 
+// Find searches for a value without modifying the tree or the comparer, so it's safe for concurrent use with other searches.
+// compare must return the BinaryTreeCompare result of the stored index against the value searched for.
+func (bt *BinaryTree) Find(compare func(storedIndex int) int) (storedIndexToValue int) {
+	if node := bt.findNode(compare); node != nil {
+		return node.value
+	}
+	return -1
+}
+
 // Insert inserts a value, or just does a search for the value if searchOnly is true, and returns the stored index to the value.
 func (bt *BinaryTree) Insert(comparer BinaryTreeComparer, indexToValue int, searchOnly bool) (storedIndexToValue int, valueAlreadyExists bool) {
 	if bt.rootNode == nil {
@@ -179,3 +189,247 @@ func (bt *BinaryTree) Insert(comparer BinaryTreeComparer, indexToValue int, sear
 		node = *nextNodePtr
 	}
 }
+
+// Count returns the number of values stored in the tree
+func (bt *BinaryTree) Count() int {
+	return bt.nodeCount
+}
+
+// findNode returns the node holding the value searched for by compare, or nil
+func (bt *BinaryTree) findNode(compare func(storedIndex int) int) *binaryTreeNode {
+	node := bt.rootNode
+	for node != nil {
+		comparisonResult := compare(node.value)
+		if comparisonResult == 0 {
+			return node
+		}
+		if comparisonResult < 0 {
+			node = node.leftright[0]
+		} else {
+			node = node.leftright[1]
+		}
+	}
+	return nil
+}
+
+// Delete removes the value searched for by compare and returns it's stored index, or -1 if it wasn't found.
+// compare must return the BinaryTreeCompare result of the stored index against the value to delete.
+func (bt *BinaryTree) Delete(compare func(storedIndex int) int) (deletedIndex int) {
+	node := bt.findNode(compare)
+	if node == nil {
+		return -1
+	}
+	deletedIndex = node.value
+	// a node with two children takes the value of the closest node on it's heavier side, which gets removed instead
+	if node.leftright[0] != nil && node.leftright[1] != nil {
+		heavySide := 0
+		if node.weight > 0 {
+			heavySide = 1
+		}
+		replacementNode := node.leftright[heavySide]
+		for replacementNode.leftright[1-heavySide] != nil {
+			replacementNode = replacementNode.leftright[1-heavySide]
+		}
+		node.value = replacementNode.value
+		node = replacementNode
+	}
+	// the node has at most one child, put the child in it's place
+	childNode := node.leftright[0]
+	if childNode == nil {
+		childNode = node.leftright[1]
+	}
+	if childNode != nil {
+		childNode.parent = node.parent
+	}
+	bt.nodeCount--
+	if node.parent == nil {
+		bt.rootNode = childNode
+		return
+	}
+	if node.parent.leftright[0] == node {
+		node.parent.leftright[0] = childNode
+		node.parent.weight++
+	} else {
+		node.parent.leftright[1] = childNode
+		node.parent.weight--
+	}
+	// do weight changes and populate rebalance list
+	node = node.parent
+	for {
+		if node.weight < -1 || node.weight > 1 {
+			bt.rebalanceList = append([]*binaryTreeNode{node}, bt.rebalanceList...)
+		}
+		if node.parent == nil {
+			break
+		}
+		if node.parent.leftright[0] == node {
+			node.parent.weight++
+		} else {
+			node.parent.weight--
+		}
+		node = node.parent
+	}
+	bt.processRebalanceList(nil)
+	return
+}
+
+// getExtremeNode returns the leftmost node of the subtree for side 0, or the rightmost for side 1
+func getExtremeNode(node *binaryTreeNode, side int) *binaryTreeNode {
+	for node != nil && node.leftright[side] != nil {
+		node = node.leftright[side]
+	}
+	return node
+}
+
+// getNextNode returns the next node in order, or the previous one when side is 0
+func getNextNode(node *binaryTreeNode, side int) *binaryTreeNode {
+	if node.leftright[side] != nil {
+		return getExtremeNode(node.leftright[side], 1-side)
+	}
+	for node.parent != nil && node.parent.leftright[side] == node {
+		node = node.parent
+	}
+	return node.parent
+}
+
+// Min returns the smallest stored index's value, or -1 if the tree is empty
+func (bt *BinaryTree) Min() int {
+	if bt.rootNode == nil {
+		return -1
+	}
+	return getExtremeNode(bt.rootNode, 0).value
+}
+
+// Max returns the largest stored index's value, or -1 if the tree is empty
+func (bt *BinaryTree) Max() int {
+	if bt.rootNode == nil {
+		return -1
+	}
+	return getExtremeNode(bt.rootNode, 1).value
+}
+
+// getBoundNode returns the node with the largest value below or equal to the value searched for when side is 0,
+// or the node with the smallest value above or equal to it when side is 1
+func (bt *BinaryTree) getBoundNode(compare func(storedIndex int) int, side int) (boundNode *binaryTreeNode) {
+	node := bt.rootNode
+	for node != nil {
+		comparisonResult := compare(node.value)
+		if comparisonResult == 0 {
+			return node
+		}
+		// a positive result means the stored value is smaller than the value searched for
+		if comparisonResult > 0 {
+			if side == 0 {
+				boundNode = node
+			}
+			node = node.leftright[1]
+		} else {
+			if side == 1 {
+				boundNode = node
+			}
+			node = node.leftright[0]
+		}
+	}
+	return
+}
+
+// Floor returns the stored index of the largest value below or equal to the value searched for by compare, or -1
+func (bt *BinaryTree) Floor(compare func(storedIndex int) int) int {
+	if node := bt.getBoundNode(compare, 0); node != nil {
+		return node.value
+	}
+	return -1
+}
+
+// Ceiling returns the stored index of the smallest value above or equal to the value searched for by compare, or -1
+func (bt *BinaryTree) Ceiling(compare func(storedIndex int) int) int {
+	if node := bt.getBoundNode(compare, 1); node != nil {
+		return node.value
+	}
+	return -1
+}
+
+// Ascend calls visit for every stored index in ascending order of their values, until visit returns false
+func (bt *BinaryTree) Ascend(visit func(storedIndex int) bool) {
+	for node := getExtremeNode(bt.rootNode, 0); node != nil && visit(node.value); node = getNextNode(node, 1) {
+	}
+}
+
+// Descend calls visit for every stored index in descending order of their values, until visit returns false
+func (bt *BinaryTree) Descend(visit func(storedIndex int) bool) {
+	for node := getExtremeNode(bt.rootNode, 1); node != nil && visit(node.value); node = getNextNode(node, 0) {
+	}
+}
+
+// AscendRange calls visit in ascending order for the stored indexes with values from the one searched for by from,
+// inclusively, to the one searched for by to, exclusively, until visit returns false. A nil from or to leaves that end open.
+func (bt *BinaryTree) AscendRange(from, to func(storedIndex int) int, visit func(storedIndex int) bool) {
+	node := getExtremeNode(bt.rootNode, 0)
+	if from != nil {
+		node = bt.getBoundNode(from, 1)
+	}
+	for ; node != nil; node = getNextNode(node, 1) {
+		if to != nil && to(node.value) <= 0 {
+			return
+		}
+		if !visit(node.value) {
+			return
+		}
+	}
+}
+
+// Validate checks the tree's structure and returns an error describing the first broken invariant found:
+// parent links, weights matching the subtree sizes and staying within -1 to 1, the node count, and the
+// ordering of the values when a comparer is given.
+func (bt *BinaryTree) Validate(comparer BinaryTreeComparer) error {
+	if bt.rootNode != nil && bt.rootNode.parent != nil {
+		return fmt.Errorf("root node %d has a parent", bt.rootNode.value)
+	}
+	if len(bt.rebalanceList) > 0 {
+		return fmt.Errorf("%d nodes left in the rebalance list", len(bt.rebalanceList))
+	}
+	nodeCount, err := validateNode(bt.rootNode)
+	if err != nil {
+		return err
+	}
+	if nodeCount != bt.nodeCount {
+		return fmt.Errorf("tree has %d nodes but it's node count is %d", nodeCount, bt.nodeCount)
+	}
+	if comparer == nil || bt.rootNode == nil {
+		return nil
+	}
+	previousNode := getExtremeNode(bt.rootNode, 0)
+	for node := getNextNode(previousNode, 1); node != nil; node = getNextNode(node, 1) {
+		if comparer.BinaryTreeCompare(previousNode.value, node.value) <= 0 {
+			return fmt.Errorf("node %d is not before node %d", previousNode.value, node.value)
+		}
+		previousNode = node
+	}
+	return nil
+}
+
+// validateNode checks the parent links and weights of the subtree and returns the number of nodes in it
+func validateNode(node *binaryTreeNode) (nodeCount int, err error) {
+	if node == nil {
+		return 0, nil
+	}
+	var sideCounts [2]int
+	for side, childNode := range node.leftright {
+		if childNode == nil {
+			continue
+		}
+		if childNode.parent != node {
+			return 0, fmt.Errorf("node %d's parent link doesn't point to node %d", childNode.value, node.value)
+		}
+		if sideCounts[side], err = validateNode(childNode); err != nil {
+			return 0, err
+		}
+	}
+	if node.weight != sideCounts[1]-sideCounts[0] {
+		return 0, fmt.Errorf("node %d has weight %d but %d nodes on it's left and %d on it's right", node.value, node.weight, sideCounts[0], sideCounts[1])
+	}
+	if node.weight < -1 || node.weight > 1 {
+		return 0, fmt.Errorf("node %d is unbalanced with weight %d", node.value, node.weight)
+	}
+	return sideCounts[0] + sideCounts[1] + 1, nil
+}
@@ -0,0 +1,186 @@
+package sortablechallengeutils
+
+import (
+	"sort"
+	"testing"
+)
+
+// testComparer stores the tree's values, negativeIndexValue is the value being inserted or searched for
+type testComparer struct {
+	values             []int
+	negativeIndexValue int
+}
+
+func (tc *testComparer) getValue(index int) int {
+	if index < 0 {
+		return tc.negativeIndexValue
+	}
+	return tc.values[index]
+}
+
+func (tc *testComparer) BinaryTreeCompare(a, b int) int {
+	aValue, bValue := tc.getValue(a), tc.getValue(b)
+	if aValue < bValue {
+		return 1
+	}
+	if aValue > bValue {
+		return -1
+	}
+	return 0
+}
+
+func (tc *testComparer) GetInsertValue() int {
+	tc.values = append(tc.values, tc.negativeIndexValue)
+	return len(tc.values) - 1
+}
+
+func (tc *testComparer) getSearch(value int) func(storedIndex int) int {
+	return func(storedIndex int) int {
+		tc.negativeIndexValue = value
+		return tc.BinaryTreeCompare(storedIndex, -1)
+	}
+}
+
+// treeTester applies operations to a tree and to a reference set of values, checking they agree
+type treeTester struct {
+	t         *testing.T
+	tree      BinaryTree
+	comparer  testComparer
+	reference map[int]bool
+}
+
+func newTreeTester(t *testing.T) *treeTester {
+	return &treeTester{t: t, reference: map[int]bool{}}
+}
+
+func (tt *treeTester) insert(value int) {
+	tt.comparer.negativeIndexValue = value
+	storedIndex, alreadyExists := tt.tree.Insert(&tt.comparer, -1, false)
+	if alreadyExists != tt.reference[value] {
+		tt.t.Fatalf("inserting %d returned alreadyExists %v", value, alreadyExists)
+	}
+	if tt.comparer.values[storedIndex] != value {
+		tt.t.Fatalf("inserting %d returned the index of %d", value, tt.comparer.values[storedIndex])
+	}
+	tt.reference[value] = true
+}
+
+func (tt *treeTester) search(value int) {
+	tt.comparer.negativeIndexValue = value
+	storedIndex, found := tt.tree.Insert(&tt.comparer, -1, true)
+	if found != tt.reference[value] || found && tt.comparer.values[storedIndex] != value {
+		tt.t.Fatalf("searching for %d returned index %d, found %v", value, storedIndex, found)
+	}
+	if storedIndex != tt.tree.Find(tt.comparer.getSearch(value)) {
+		tt.t.Fatalf("Find and Insert disagree on the index of %d", value)
+	}
+}
+
+func (tt *treeTester) delete(value int) {
+	deletedIndex := tt.tree.Delete(tt.comparer.getSearch(value))
+	if deletedIndex >= 0 != tt.reference[value] || deletedIndex >= 0 && tt.comparer.values[deletedIndex] != value {
+		tt.t.Fatalf("deleting %d returned index %d", value, deletedIndex)
+	}
+	delete(tt.reference, value)
+}
+
+func (tt *treeTester) validate() {
+	if err := tt.tree.Validate(&tt.comparer); err != nil {
+		tt.t.Fatal(err)
+	}
+	if tt.tree.Count() != len(tt.reference) {
+		tt.t.Fatalf("tree has %d values, expected %d", tt.tree.Count(), len(tt.reference))
+	}
+}
+
+// validateOrder checks the iterations and bounds against the sorted reference values
+func (tt *treeTester) validateOrder() {
+	sortedValues := []int{}
+	for value := range tt.reference {
+		sortedValues = append(sortedValues, value)
+	}
+	sort.Ints(sortedValues)
+	ascendingValues := []int{}
+	tt.tree.Ascend(func(storedIndex int) bool {
+		ascendingValues = append(ascendingValues, tt.comparer.values[storedIndex])
+		return true
+	})
+	descendingValues := []int{}
+	tt.tree.Descend(func(storedIndex int) bool {
+		descendingValues = append([]int{tt.comparer.values[storedIndex]}, descendingValues...)
+		return true
+	})
+	if len(ascendingValues) != len(sortedValues) || len(descendingValues) != len(sortedValues) {
+		tt.t.Fatalf("iterated over %d and %d values, expected %d", len(ascendingValues), len(descendingValues), len(sortedValues))
+	}
+	for valueIndex, value := range sortedValues {
+		if ascendingValues[valueIndex] != value || descendingValues[valueIndex] != value {
+			tt.t.Fatalf("iteration out of order at %d", valueIndex)
+		}
+	}
+	if len(sortedValues) == 0 {
+		return
+	}
+	if tt.comparer.values[tt.tree.Min()] != sortedValues[0] || tt.comparer.values[tt.tree.Max()] != sortedValues[len(sortedValues)-1] {
+		tt.t.Fatal("Min or Max returned the wrong value")
+	}
+	for value := sortedValues[0] - 1; value <= sortedValues[len(sortedValues)-1]+1; value++ {
+		floorIndex := tt.tree.Floor(tt.comparer.getSearch(value))
+		ceilingIndex := tt.tree.Ceiling(tt.comparer.getSearch(value))
+		valueIndex := sort.SearchInts(sortedValues, value)
+		if valueIndex < len(sortedValues) && sortedValues[valueIndex] == value {
+			if floorIndex < 0 || tt.comparer.values[floorIndex] != value || ceilingIndex < 0 || tt.comparer.values[ceilingIndex] != value {
+				tt.t.Fatalf("Floor or Ceiling of stored value %d is wrong", value)
+			}
+			continue
+		}
+		if valueIndex == 0 && floorIndex >= 0 || valueIndex > 0 && (floorIndex < 0 || tt.comparer.values[floorIndex] != sortedValues[valueIndex-1]) {
+			tt.t.Fatalf("Floor of %d is wrong", value)
+		}
+		if valueIndex == len(sortedValues) && ceilingIndex >= 0 || valueIndex < len(sortedValues) && (ceilingIndex < 0 || tt.comparer.values[ceilingIndex] != sortedValues[valueIndex]) {
+			tt.t.Fatalf("Ceiling of %d is wrong", value)
+		}
+	}
+	from, to := sortedValues[0]+1, sortedValues[len(sortedValues)-1]
+	rangeCount := 0
+	tt.tree.AscendRange(tt.comparer.getSearch(from), tt.comparer.getSearch(to), func(storedIndex int) bool {
+		if value := tt.comparer.values[storedIndex]; value < from || value >= to {
+			tt.t.Fatalf("AscendRange from %d to %d visited %d", from, to, value)
+		}
+		rangeCount++
+		return true
+	})
+	if expectedCount := max(sort.SearchInts(sortedValues, to)-sort.SearchInts(sortedValues, from), 0); rangeCount != expectedCount {
+		tt.t.Fatalf("AscendRange from %d to %d visited %d values, expected %d", from, to, rangeCount, expectedCount)
+	}
+}
+
+func TestBinaryTreeRandomOperations(t *testing.T) {
+	runRandomOperations(func() operationTester { return newTreeTester(t) })
+}
+
+func TestBinaryTreeSortedInsertsAndDeletes(t *testing.T) {
+	tt := newTreeTester(t)
+	for value := 0; value < 500; value++ {
+		tt.insert(value)
+		tt.validate()
+	}
+	tt.validateOrder()
+	for value := 0; value < 500; value += 2 {
+		tt.delete(value)
+		tt.validate()
+	}
+	tt.validateOrder()
+	for value := 499; value >= 0; value-- {
+		tt.delete(value)
+		tt.validate()
+	}
+}
+
+func FuzzBinaryTree(f *testing.F) {
+	f.Add([]byte{0, 1, 0, 2, 0, 3, 3, 2, 2, 1})
+	f.Add([]byte{0, 5, 0, 4, 0, 3, 0, 2, 0, 1, 3, 4, 3, 1, 2, 3})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		runOperations(newTreeTester(t), data)
+	})
+}
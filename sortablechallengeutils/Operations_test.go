@@ -0,0 +1,40 @@
+package sortablechallengeutils
+
+import "math/rand"
+
+// operationTester applies operations to a data structure and to a reference, checking they agree
+type operationTester interface {
+	insert(value int)
+	search(value int)
+	delete(value int)
+	validate()
+	validateOrder()
+}
+
+// runOperations applies the operations encoded in data, two bytes per operation for the operation and the value,
+// validating after each operation and checking the order once they are done
+func runOperations(tester operationTester, data []byte) {
+	for dataIndex := 0; dataIndex+1 < len(data); dataIndex += 2 {
+		value := int(data[dataIndex+1])
+		switch data[dataIndex] % 4 {
+		case 0, 1:
+			tester.insert(value)
+		case 2:
+			tester.search(value)
+		case 3:
+			tester.delete(value)
+		}
+		tester.validate()
+	}
+	tester.validateOrder()
+}
+
+// runRandomOperations runs seeded random operations on new testers, so that failures can be reproduced
+func runRandomOperations(newTester func() operationTester) {
+	for seed := int64(0); seed < 200; seed++ {
+		random := rand.New(rand.NewSource(seed))
+		data := make([]byte, 2*random.Intn(1000))
+		random.Read(data)
+		runOperations(newTester(), data)
+	}
+}
@@ -0,0 +1,131 @@
+package sortablechallengeutils
+
+// OrderedMap is a map sorted by it's keys, built on the weight-balanced BinaryTree.
+// Lookups and iterations can run from concurrent goroutines, as long as nothing is being put or deleted.
+type OrderedMap[K any, V any] struct {
+	tree        BinaryTree
+	keys        []K
+	values      []V
+	freeIndexes []int // indexes of deleted entries, reused by Put
+	compare     func(a, b K) int
+}
+
+// NewOrderedMap returns an empty map sorted with compare, which needs to return a negative number
+// if a is before b, a positive number if a is after b, and 0 if they're equal
+func NewOrderedMap[K any, V any](compare func(a, b K) int) *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{compare: compare}
+}
+
+// orderedMapInserter is the BinaryTreeComparer for a single Put, so no search state is shared between calls
+type orderedMapInserter[K any, V any] struct {
+	om    *OrderedMap[K, V]
+	key   K
+	value V
+}
+
+// BinaryTreeCompare used by BinaryTree.go, negative indexes stand for the key being put
+func (omi *orderedMapInserter[K, V]) BinaryTreeCompare(a, b int) int {
+	aKey, bKey := omi.key, omi.key
+	if a >= 0 {
+		aKey = omi.om.keys[a]
+	}
+	if b >= 0 {
+		bKey = omi.om.keys[b]
+	}
+	return omi.om.compare(bKey, aKey)
+}
+
+// GetInsertValue used by BinaryTree.go, stores the entry being put and returns it's index
+func (omi *orderedMapInserter[K, V]) GetInsertValue() (index int) {
+	om := omi.om
+	if len(om.freeIndexes) > 0 {
+		index = om.freeIndexes[len(om.freeIndexes)-1]
+		om.freeIndexes = om.freeIndexes[:len(om.freeIndexes)-1]
+		om.keys[index] = omi.key
+		om.values[index] = omi.value
+		return index
+	}
+	om.keys = append(om.keys, omi.key)
+	om.values = append(om.values, omi.value)
+	return len(om.keys) - 1
+}
+
+// getKeyComparer returns the function used to search the tree for a key
+func (om *OrderedMap[K, V]) getKeyComparer(key K) func(storedIndex int) int {
+	return func(storedIndex int) int {
+		return om.compare(key, om.keys[storedIndex])
+	}
+}
+
+// Len returns the number of entries in the map
+func (om *OrderedMap[K, V]) Len() int {
+	return om.tree.Count()
+}
+
+// Get returns the value stored for the key, and false if there's none
+func (om *OrderedMap[K, V]) Get(key K) (value V, found bool) {
+	storedIndex := om.tree.Find(om.getKeyComparer(key))
+	if storedIndex < 0 {
+		return value, false
+	}
+	return om.values[storedIndex], true
+}
+
+// Put stores the value for the key, returning true if it replaced an existing value
+func (om *OrderedMap[K, V]) Put(key K, value V) (replaced bool) {
+	storedIndex, replaced := om.tree.Insert(&orderedMapInserter[K, V]{om: om, key: key, value: value}, -1, false)
+	if replaced {
+		om.values[storedIndex] = value
+	}
+	return replaced
+}
+
+// Delete removes the key and returns the value that was stored for it, and false if there was none
+func (om *OrderedMap[K, V]) Delete(key K) (value V, found bool) {
+	storedIndex := om.tree.Delete(om.getKeyComparer(key))
+	if storedIndex < 0 {
+		return value, false
+	}
+	value = om.values[storedIndex]
+	// clear the entry so it doesn't hold on to anything until it's reused
+	var emptyKey K
+	var emptyValue V
+	om.keys[storedIndex] = emptyKey
+	om.values[storedIndex] = emptyValue
+	om.freeIndexes = append(om.freeIndexes, storedIndex)
+	return value, true
+}
+
+// Ascend calls visit for every entry in ascending key order, until visit returns false
+func (om *OrderedMap[K, V]) Ascend(visit func(key K, value V) bool) {
+	om.tree.Ascend(func(storedIndex int) bool {
+		return visit(om.keys[storedIndex], om.values[storedIndex])
+	})
+}
+
+// Descend calls visit for every entry in descending key order, until visit returns false
+func (om *OrderedMap[K, V]) Descend(visit func(key K, value V) bool) {
+	om.tree.Descend(func(storedIndex int) bool {
+		return visit(om.keys[storedIndex], om.values[storedIndex])
+	})
+}
+
+// AscendFrom calls visit in ascending key order for the entries with keys from the given one onwards, until visit returns false
+func (om *OrderedMap[K, V]) AscendFrom(from K, visit func(key K, value V) bool) {
+	om.tree.AscendRange(om.getKeyComparer(from), nil, func(storedIndex int) bool {
+		return visit(om.keys[storedIndex], om.values[storedIndex])
+	})
+}
+
+// Range calls visit in ascending key order for the entries with keys from from, inclusively, to to, exclusively,
+// until visit returns false
+func (om *OrderedMap[K, V]) Range(from, to K, visit func(key K, value V) bool) {
+	om.tree.AscendRange(om.getKeyComparer(from), om.getKeyComparer(to), func(storedIndex int) bool {
+		return visit(om.keys[storedIndex], om.values[storedIndex])
+	})
+}
+
+// Validate checks the structure and ordering of the map's tree, see BinaryTree.Validate
+func (om *OrderedMap[K, V]) Validate() error {
+	return om.tree.Validate(&orderedMapInserter[K, V]{om: om})
+}
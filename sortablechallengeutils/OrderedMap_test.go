@@ -0,0 +1,153 @@
+package sortablechallengeutils
+
+import (
+	"cmp"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// mapTester applies operations to an ordered map and to a reference map, checking they agree. Inserting puts the key
+// with a new value, searching gets it.
+type mapTester struct {
+	t         *testing.T
+	om        *OrderedMap[int, string]
+	reference map[int]string
+	puts      int // counts the puts, so replaced values can be told apart
+}
+
+func newMapTester(t *testing.T) *mapTester {
+	return &mapTester{t: t, om: NewOrderedMap[int, string](cmp.Compare[int]), reference: map[int]string{}}
+}
+
+func (mt *mapTester) insert(key int) {
+	mt.puts++
+	value := strconv.Itoa(key) + "/" + strconv.Itoa(mt.puts)
+	freeIndexCount, storedCount := len(mt.om.freeIndexes), len(mt.om.keys)
+	_, exists := mt.reference[key]
+	if replaced := mt.om.Put(key, value); replaced != exists {
+		mt.t.Fatalf("putting %d returned replaced %v", key, replaced)
+	}
+	// a new key reuses a deleted entry before growing the slices
+	if !exists && freeIndexCount > 0 && (len(mt.om.freeIndexes) != freeIndexCount-1 || len(mt.om.keys) != storedCount) {
+		mt.t.Fatalf("putting %d didn't reuse a free index", key)
+	}
+	mt.reference[key] = value
+}
+
+func (mt *mapTester) search(key int) {
+	value, found := mt.om.Get(key)
+	if referenceValue, exists := mt.reference[key]; found != exists || value != referenceValue {
+		mt.t.Fatalf("getting %d returned %q, %v, expected %q", key, value, found, referenceValue)
+	}
+}
+
+func (mt *mapTester) delete(key int) {
+	value, found := mt.om.Delete(key)
+	if referenceValue, exists := mt.reference[key]; found != exists || value != referenceValue {
+		mt.t.Fatalf("deleting %d returned %q, %v, expected %q", key, value, found, referenceValue)
+	}
+	delete(mt.reference, key)
+}
+
+func (mt *mapTester) validate() {
+	if err := mt.om.Validate(); err != nil {
+		mt.t.Fatal(err)
+	}
+	if mt.om.Len() != len(mt.reference) {
+		mt.t.Fatalf("map has %d entries, expected %d", mt.om.Len(), len(mt.reference))
+	}
+	if len(mt.om.keys) != mt.om.Len()+len(mt.om.freeIndexes) {
+		mt.t.Fatalf("map stores %d entries for %d keys and %d free indexes", len(mt.om.keys), mt.om.Len(), len(mt.om.freeIndexes))
+	}
+}
+
+// validateOrder checks the iterations against the sorted reference keys
+func (mt *mapTester) validateOrder() {
+	sortedKeys := []int{}
+	for key := range mt.reference {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Ints(sortedKeys)
+	ascendingKeys := []int{}
+	mt.om.Ascend(func(key int, value string) bool {
+		if value != mt.reference[key] {
+			mt.t.Fatalf("Ascend visited %d with %q, expected %q", key, value, mt.reference[key])
+		}
+		ascendingKeys = append(ascendingKeys, key)
+		return true
+	})
+	descendingKeys := []int{}
+	mt.om.Descend(func(key int, value string) bool {
+		descendingKeys = append([]int{key}, descendingKeys...)
+		return true
+	})
+	if len(ascendingKeys) != len(sortedKeys) || len(descendingKeys) != len(sortedKeys) {
+		mt.t.Fatalf("iterated over %d and %d keys, expected %d", len(ascendingKeys), len(descendingKeys), len(sortedKeys))
+	}
+	for keyIndex, key := range sortedKeys {
+		if ascendingKeys[keyIndex] != key || descendingKeys[keyIndex] != key {
+			mt.t.Fatalf("iteration out of order at %d", keyIndex)
+		}
+	}
+	if len(sortedKeys) == 0 {
+		return
+	}
+	from, to := sortedKeys[0]+1, sortedKeys[len(sortedKeys)-1]
+	rangeKeys := []int{}
+	mt.om.Range(from, to, func(key int, value string) bool {
+		rangeKeys = append(rangeKeys, key)
+		return true
+	})
+	fromIndex := sort.SearchInts(sortedKeys, from)
+	if expectedCount := max(sort.SearchInts(sortedKeys, to)-fromIndex, 0); len(rangeKeys) != expectedCount {
+		mt.t.Fatalf("Range from %d to %d visited %d keys, expected %d", from, to, len(rangeKeys), expectedCount)
+	}
+	for keyIndex, key := range rangeKeys {
+		if key != sortedKeys[fromIndex+keyIndex] {
+			mt.t.Fatalf("Range from %d to %d visited %d at %d", from, to, key, keyIndex)
+		}
+	}
+}
+
+func TestOrderedMapRandomOperations(t *testing.T) {
+	runRandomOperations(func() operationTester { return newMapTester(t) })
+}
+
+func TestOrderedMapPutDeleteAndPutAgain(t *testing.T) {
+	mt := newMapTester(t)
+	for key := 0; key < 200; key++ {
+		mt.insert(key)
+	}
+	mt.validate()
+	for key := 0; key < 200; key += 3 {
+		mt.delete(key)
+		mt.validate()
+	}
+	mt.validateOrder()
+	// the deleted keys come back, and some replace the values still stored
+	for key := 199; key >= 0; key -= 2 {
+		mt.insert(key)
+		mt.validate()
+	}
+	mt.validateOrder()
+	for key := 0; key < 200; key++ {
+		mt.search(key)
+	}
+	for key := 0; key < 200; key++ {
+		mt.delete(key)
+		mt.validate()
+	}
+	mt.validateOrder()
+	if len(mt.om.freeIndexes) != len(mt.om.keys) {
+		t.Fatalf("empty map has %d free indexes for %d entries", len(mt.om.freeIndexes), len(mt.om.keys))
+	}
+}
+
+func FuzzOrderedMap(f *testing.F) {
+	f.Add([]byte{0, 1, 0, 2, 3, 1, 0, 1, 2, 1, 2, 2})
+	f.Add([]byte{0, 5, 0, 4, 0, 3, 3, 4, 3, 5, 0, 6, 0, 4, 2, 4})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		runOperations(newMapTester(t), data)
+	})
+}